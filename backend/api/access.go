@@ -0,0 +1,277 @@
+// backend/api/access.go
+package api
+
+import (
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"gorm.io/gorm"
+)
+
+// pageAccess holds the fields of a document that decide who can access it
+type pageAccess struct {
+	ID         uint
+	AuthorID   uint
+	IsPublic   bool
+	Restricted bool
+	ParentID   *uint
+	SpaceID    *uint
+}
+
+// accessResolver computes the effective permission level of one user on
+// documents. A page's own grants, to the user or one of their groups, win;
+// otherwise, unless the page is restricted, it inherits from its parent page
+// and finally from its space.
+// Authors always have full access and public pages can be read by anyone.
+type accessResolver struct {
+	db          *gorm.DB
+	user        models.User
+	pageGrants  map[uint]models.PermissionLevel
+	groupGrants map[uint]models.PermissionLevel
+	spaceGrants map[uint]models.PermissionLevel
+	pages       map[uint]*pageAccess
+	levels      map[uint]models.PermissionLevel
+}
+
+// newAccessResolver loads the user's grants so access to many documents can be
+// resolved without further queries per grant. Documents and spaces are only
+// looked up through db, so they stay within its workspace.
+func newAccessResolver(db *gorm.DB, user models.User) *accessResolver {
+	r := &accessResolver{
+		db:          db,
+		user:        user,
+		pageGrants:  make(map[uint]models.PermissionLevel),
+		groupGrants: make(map[uint]models.PermissionLevel),
+		spaceGrants: make(map[uint]models.PermissionLevel),
+		pages:       make(map[uint]*pageAccess),
+		levels:      make(map[uint]models.PermissionLevel),
+	}
+
+	// Expired grants are ignored even before the sweeper deletes them
+	var permissions []models.Permission
+	db.Where("user_id = ? AND (expires_at IS NULL OR expires_at > ?)", user.ID, time.Now()).Find(&permissions)
+	for _, permission := range permissions {
+		r.pageGrants[permission.DocumentID] = permission.Level
+	}
+
+	// Grants to the user's groups, keeping the highest level per document
+	groupIDs := db.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", user.ID)
+	var groupPermissions []models.GroupPermission
+	db.Where("group_id IN (?)", groupIDs).Find(&groupPermissions)
+	for _, permission := range groupPermissions {
+		if current, ok := r.groupGrants[permission.DocumentID]; !ok || permission.Level.Rank() > current.Rank() {
+			r.groupGrants[permission.DocumentID] = permission.Level
+		}
+	}
+
+	var spacePermissions []models.SpacePermission
+	db.Where("user_id = ?", user.ID).Find(&spacePermissions)
+	for _, permission := range spacePermissions {
+		r.spaceGrants[permission.SpaceID] = permission.Level
+	}
+
+	var ownedSpaceIDs []uint
+	db.Model(&models.Space{}).Where("owner_id = ?", user.ID).Pluck("id", &ownedSpaceIDs)
+	for _, id := range ownedSpaceIDs {
+		r.spaceGrants[id] = models.AdminPermission
+	}
+
+	return r
+}
+
+// level returns the user's effective permission level on a document
+func (r *accessResolver) level(document models.Document) models.PermissionLevel {
+	r.pages[document.ID] = &pageAccess{
+		ID:         document.ID,
+		AuthorID:   document.AuthorID,
+		IsPublic:   document.IsPublic,
+		Restricted: document.Restricted,
+		ParentID:   document.ParentID,
+		SpaceID:    document.SpaceID,
+	}
+	return r.pageLevel(document.ID)
+}
+
+func (r *accessResolver) pageLevel(id uint) models.PermissionLevel {
+	if level, ok := r.levels[id]; ok {
+		return level
+	}
+
+	page := r.page(id)
+	if page == nil {
+		return models.NoPermission
+	}
+
+	level := r.inheritedLevel(page)
+	if page.AuthorID == r.user.ID {
+		level = models.AdminPermission
+	}
+	if page.IsPublic && !level.Includes(models.ViewPermission) {
+		level = models.ViewPermission
+	}
+
+	r.levels[id] = level
+	return level
+}
+
+// inheritedLevel walks up the page tree until it finds a grant for the user
+func (r *accessResolver) inheritedLevel(page *pageAccess) models.PermissionLevel {
+	visited := make(map[uint]bool)
+	for page != nil && !visited[page.ID] {
+		visited[page.ID] = true
+
+		// A grant to the user on a page overrides grants to their groups
+		if level, ok := r.pageGrants[page.ID]; ok {
+			return level
+		}
+		if level, ok := r.groupGrants[page.ID]; ok {
+			return level
+		}
+		if page.Restricted {
+			return models.NoPermission
+		}
+		if page.ParentID == nil {
+			if page.SpaceID != nil {
+				if level, ok := r.spaceGrants[*page.SpaceID]; ok {
+					return level
+				}
+			}
+			return models.NoPermission
+		}
+		page = r.page(*page.ParentID)
+	}
+	return models.NoPermission
+}
+
+// page returns the access fields of a document, loading it if needed
+func (r *accessResolver) page(id uint) *pageAccess {
+	if page, ok := r.pages[id]; ok {
+		return page
+	}
+	var page pageAccess
+	if err := r.db.Model(&models.Document{}).First(&page, id).Error; err != nil {
+		r.pages[id] = nil
+		return nil
+	}
+	r.pages[id] = &page
+	return &page
+}
+
+// canViewDocument reports whether the user may read the document
+func canViewDocument(db *gorm.DB, document models.Document, user models.User) bool {
+	return newAccessResolver(db, user).level(document).Includes(models.ViewPermission)
+}
+
+// canEditDocument reports whether the user may change the document
+func canEditDocument(db *gorm.DB, document models.Document, user models.User) bool {
+	return newAccessResolver(db, user).level(document).Includes(models.EditPermission)
+}
+
+// canManageDocument reports whether the user may share the document and
+// change its settings: they own it or were made an admin of it
+func canManageDocument(db *gorm.DB, document models.Document, user models.User) bool {
+	return newAccessResolver(db, user).level(document).Includes(models.AdminPermission)
+}
+
+// userGrantSQL matches the unexpired grants to the user on a page
+func userGrantSQL(page string) string {
+	return "SELECT 1 FROM permissions WHERE permissions.document_id = " + page +
+		" AND permissions.user_id = @user AND permissions.deleted_at IS NULL" +
+		" AND (permissions.expires_at IS NULL OR permissions.expires_at > @now)"
+}
+
+// groupGrantSQL matches the grants to the user's groups on a page
+func groupGrantSQL(page string) string {
+	return "SELECT 1 FROM group_permissions JOIN group_members ON group_members.group_id = group_permissions.group_id" +
+		" AND group_members.user_id = @user AND group_members.deleted_at IS NULL" +
+		" WHERE group_permissions.document_id = " + page + " AND group_permissions.deleted_at IS NULL"
+}
+
+// grantedPagesSQL selects the pages where the user has one of @levels through
+// grants, resolved like accessResolver does. It starts from the pages whose
+// own grants give such a level, or top-level pages whose space does, and
+// descends into children that are not restricted and have no grants of their
+// own, so the database never has to return every document.
+var grantedPagesSQL = `WITH RECURSIVE granted (id) AS (
+	SELECT documents.id FROM documents
+	WHERE documents.deleted_at IS NULL AND (
+		EXISTS (` + userGrantSQL("documents.id") + ` AND permissions.level IN @levels)
+		OR (NOT EXISTS (` + userGrantSQL("documents.id") + `)
+			AND EXISTS (` + groupGrantSQL("documents.id") + ` AND group_permissions.level IN @levels))
+		OR (documents.parent_id IS NULL AND NOT documents.restricted
+			AND NOT EXISTS (` + userGrantSQL("documents.id") + `)
+			AND NOT EXISTS (` + groupGrantSQL("documents.id") + `)
+			AND (EXISTS (SELECT 1 FROM space_permissions WHERE space_permissions.space_id = documents.space_id
+					AND space_permissions.user_id = @user AND space_permissions.deleted_at IS NULL
+					AND space_permissions.level IN @levels)
+				OR EXISTS (SELECT 1 FROM spaces WHERE spaces.id = documents.space_id
+					AND spaces.owner_id = @user AND spaces.deleted_at IS NULL)))
+	)
+	UNION
+	SELECT child.id FROM documents child JOIN granted ON child.parent_id = granted.id
+	WHERE child.deleted_at IS NULL AND NOT child.restricted
+		AND NOT EXISTS (` + userGrantSQL("child.id") + `)
+		AND NOT EXISTS (` + groupGrantSQL("child.id") + `)
+)
+SELECT id FROM granted`
+
+// levelsIncluding lists the grantable levels that give at least the access of level
+func levelsIncluding(level models.PermissionLevel) []string {
+	levels := []string{}
+	for _, candidate := range []models.PermissionLevel{models.ViewPermission, models.CommentPermission, models.EditPermission, models.AdminPermission} {
+		if candidate.Includes(level) {
+			levels = append(levels, string(candidate))
+		}
+	}
+	return levels
+}
+
+// documentsWithLevel returns a query over the documents on which the user has
+// at least the given level
+func documentsWithLevel(db *gorm.DB, user models.User, level models.PermissionLevel) *gorm.DB {
+	condition := "documents.author_id = @user OR documents.id IN (" + grantedPagesSQL + ")"
+	if models.ViewPermission.Includes(level) {
+		condition += " OR documents.is_public = @public"
+	}
+	return db.Model(&models.Document{}).Where("("+condition+")", map[string]interface{}{
+		"user":   user.ID,
+		"now":    time.Now(),
+		"levels": levelsIncluding(level),
+		"public": true,
+	})
+}
+
+// viewableDocuments returns a query over the documents the user can read
+func viewableDocuments(db *gorm.DB, user models.User) *gorm.DB {
+	return documentsWithLevel(db, user, models.ViewPermission)
+}
+
+// viewableSpaces returns a query over the spaces the user owns, was granted
+// access to, or can see at least one page of
+func viewableSpaces(db *gorm.DB, user models.User) *gorm.DB {
+	granted := db.Model(&models.SpacePermission{}).Select("space_id").
+		Where("user_id = ? AND level IN ?", user.ID, levelsIncluding(models.ViewPermission))
+	withPages := viewableDocuments(db, user).Select("space_id").Where("space_id IS NOT NULL")
+	return db.Model(&models.Space{}).
+		Where("spaces.owner_id = ? OR spaces.id IN (?) OR spaces.id IN (?)", user.ID, granted, withPages)
+}
+
+// canViewSpace reports whether the user can see the space
+func canViewSpace(db *gorm.DB, space models.Space, user models.User) bool {
+	if space.OwnerID == user.ID {
+		return true
+	}
+	var count int64
+	viewableSpaces(db, user).Where("spaces.id = ?", space.ID).Count(&count)
+	return count > 0
+}
+
+// canEditSpace reports whether the user may add pages to the top of a space
+func canEditSpace(db *gorm.DB, space models.Space, user models.User) bool {
+	return space.OwnerID == user.ID || newAccessResolver(db, user).spaceGrants[space.ID].Includes(models.EditPermission)
+}
+
+// canManageSpace reports whether the user may change who has access to a space
+func canManageSpace(db *gorm.DB, space models.Space, user models.User) bool {
+	return space.OwnerID == user.ID || newAccessResolver(db, user).spaceGrants[space.ID] == models.AdminPermission
+}
@@ -0,0 +1,259 @@
+// backend/api/access_request_controller.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RequestAccess asks the people who manage a document for access to it. The
+// document's author is notified and the request shows up in the inbox of
+// everyone who can manage the document.
+func RequestAccess(c *gin.Context) {
+	var body struct {
+		Level   models.PermissionLevel `json:"level"`
+		Message string                 `json:"message"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if body.Level == "" {
+		body.Level = models.ViewPermission
+	}
+	if !body.Level.IsGrantable() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidGrantLevelMessage})
+		return
+	}
+
+	docIdUint := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, docIdUint).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if newAccessResolver(workspaceDB(c), user).level(document).Includes(body.Level) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You already have this access to the document"})
+		return
+	}
+
+	var pending int64
+	workspaceDB(c).Model(&models.AccessRequest{}).
+		Where("document_id = ? AND requester_id = ? AND status = ?", document.ID, user.ID, models.AccessRequestPending).
+		Count(&pending)
+	if pending > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You already have a pending request for this document"})
+		return
+	}
+
+	request := models.AccessRequest{
+		DocumentID:  document.ID,
+		RequesterID: user.ID,
+		Level:       body.Level,
+		Message:     body.Message,
+		Status:      models.AccessRequestPending,
+	}
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&request).Error; err != nil {
+			return err
+		}
+		message := fmt.Sprintf("%s requested %s access to \"%s\"", user.Name, body.Level, document.Title)
+		return notify(tx, document.AuthorID, models.NotificationAccessRequested, message, &document.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request access"})
+		return
+	}
+
+	request.Requester = user
+	c.JSON(http.StatusCreated, request)
+}
+
+// GetDocumentAccessRequests lists the pending access requests for a document
+func GetDocumentAccessRequests(c *gin.Context) {
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+
+	var requests []models.AccessRequest
+	workspaceDB(c).Preload("Requester").
+		Where("document_id = ? AND status = ?", document.ID, models.AccessRequestPending).
+		Order("created_at").
+		Find(&requests)
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetAccessRequestInbox lists the pending access requests for every document
+// the authenticated user can manage
+func GetAccessRequestInbox(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	// Documents in the trash are left out, as their requests cannot be decided
+	managed := documentsWithLevel(workspaceDB(c), user, models.AdminPermission).Select("id")
+	inbox := []models.AccessRequest{}
+	result := workspaceDB(c).Preload("Document").Preload("Requester").
+		Where("status = ? AND document_id IN (?)", models.AccessRequestPending, managed).
+		Order("created_at").
+		Find(&inbox)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve access requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, inbox)
+}
+
+// ApproveAccessRequest grants the requester access to the document and lets
+// them know. The level asked for can be overridden, and the grant may be
+// given an expiry.
+func ApproveAccessRequest(c *gin.Context) {
+	var body struct {
+		Level     models.PermissionLevel `json:"level"`
+		ExpiresAt *time.Time             `json:"expiresAt"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if body.Level != "" && !body.Level.IsGrantable() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidGrantLevelMessage})
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be in the future"})
+		return
+	}
+
+	request, currentUser, ok := loadAccessRequestToDecide(c)
+	if !ok {
+		return
+	}
+	if !requireVerifiedEmail(c, currentUser) {
+		return
+	}
+	if body.Level == "" {
+		body.Level = request.Level
+	}
+
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := decideAccessRequest(tx, &request, models.AccessRequestApproved, currentUser); err != nil {
+			return err
+		}
+
+		permission := models.Permission{UserID: request.RequesterID, DocumentID: request.DocumentID}
+		err := tx.Where(permission).
+			Assign(map[string]interface{}{
+				"level":         body.Level,
+				"granted_by_id": currentUser.ID,
+				"expires_at":    body.ExpiresAt,
+			}).
+			FirstOrCreate(&permission).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&models.PermissionEvent{
+			DocumentID: request.DocumentID,
+			UserID:     &request.RequesterID,
+			Action:     models.PermissionGranted,
+			Level:      body.Level,
+			ExpiresAt:  body.ExpiresAt,
+			ActorID:    &currentUser.ID,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("%s gave you %s access to \"%s\"", currentUser.Name, body.Level, request.Document.Title)
+		return notify(tx, request.RequesterID, models.NotificationAccessApproved, message, &request.DocumentID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve access request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// DenyAccessRequest turns down an access request and lets the requester know
+func DenyAccessRequest(c *gin.Context) {
+	request, currentUser, ok := loadAccessRequestToDecide(c)
+	if !ok {
+		return
+	}
+
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := decideAccessRequest(tx, &request, models.AccessRequestDenied, currentUser); err != nil {
+			return err
+		}
+		message := fmt.Sprintf("Your request for access to \"%s\" was declined", request.Document.Title)
+		return notify(tx, request.RequesterID, models.NotificationAccessDenied, message, &request.DocumentID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deny access request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// loadAccessRequestToDecide loads the pending access request of the route and
+// checks the authenticated user can manage its document
+func loadAccessRequestToDecide(c *gin.Context) (models.AccessRequest, models.User, bool) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var request models.AccessRequest
+	requestID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Access request not found"})
+		return request, user, false
+	}
+	if err := workspaceDB(c).Preload("Document").Preload("Requester").First(&request, requestID).Error; err != nil || request.Document == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Access request not found"})
+		return request, user, false
+	}
+
+	if !canManageDocument(workspaceDB(c), *request.Document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to manage access to this document"})
+		return request, user, false
+	}
+
+	if request.Status != models.AccessRequestPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "This access request has already been decided"})
+		return request, user, false
+	}
+	return request, user, true
+}
+
+// decideAccessRequest records the decision on a pending access request
+func decideAccessRequest(tx *gorm.DB, request *models.AccessRequest, status models.AccessRequestStatus, decidedBy models.User) error {
+	now := time.Now()
+	err := tx.Model(&models.AccessRequest{}).Where("id = ?", request.ID).Updates(map[string]interface{}{
+		"status":        status,
+		"decided_by_id": decidedBy.ID,
+		"decided_at":    now,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	request.Status = status
+	request.DecidedByID = &decidedBy.ID
+	request.DecidedBy = &decidedBy
+	request.DecidedAt = &now
+	return nil
+}
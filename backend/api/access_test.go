@@ -0,0 +1,198 @@
+// backend/api/access_test.go
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB opens an empty SQLite database with every model migrated. It is
+// also installed as config.DB and its queries run in workspace 1.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := tenancy.Register(db); err != nil {
+		t.Fatalf("register tenancy: %v", err)
+	}
+	err = db.AutoMigrate(
+		&models.User{}, &models.Document{}, &models.Permission{}, &models.Version{},
+		&models.Space{}, &models.SpacePermission{}, &models.Group{}, &models.GroupMember{},
+		&models.GroupPermission{}, &models.PermissionEvent{}, &models.AccessRequest{},
+		&models.Notification{}, &models.ShareLink{}, &models.Session{}, &models.RefreshToken{},
+		&models.UserToken{}, &models.RecoveryCode{}, &models.UserIdentity{},
+		&models.PersonalAccessToken{}, &models.LoginAttempt{}, &models.Workspace{},
+		&models.WorkspaceMember{}, &models.WorkspaceInvitation{},
+	)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() { config.DB = previous })
+	return db.WithContext(tenancy.WithWorkspace(context.Background(), 1))
+}
+
+// mustCreate inserts rows and fails the test if that does not work
+func mustCreate(t *testing.T, db *gorm.DB, rows ...interface{}) {
+	t.Helper()
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("create %T: %v", row, err)
+		}
+	}
+}
+
+func TestDocumentsWithLevel(t *testing.T) {
+	db := openTestDB(t)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com", Password: "x"}
+	reader := models.User{Name: "Reader", Email: "reader@example.com", Password: "x"}
+	mustCreate(t, db, &owner, &reader)
+
+	space := models.Space{Name: "Team", OwnerID: owner.ID}
+	group := models.Group{Name: "Editors", CreatedByID: owner.ID}
+	mustCreate(t, db, &space, &group)
+	mustCreate(t, db,
+		&models.SpacePermission{UserID: reader.ID, SpaceID: space.ID, Level: models.ViewPermission},
+		&models.GroupMember{GroupID: group.ID, UserID: reader.ID},
+	)
+
+	// page creates a document by the owner, in the space unless it is public
+	page := func(title string, parent *models.Document, restricted, public bool) *models.Document {
+		document := &models.Document{Title: title, AuthorID: owner.ID, Restricted: restricted, IsPublic: public}
+		if !public {
+			document.SpaceID = &space.ID
+		}
+		if parent != nil {
+			document.ParentID = &parent.ID
+		}
+		mustCreate(t, db, document)
+		return document
+	}
+	grant := func(document *models.Document, level models.PermissionLevel, expiresAt *time.Time) {
+		mustCreate(t, db, &models.Permission{UserID: reader.ID, DocumentID: document.ID, Level: level, ExpiresAt: expiresAt})
+	}
+
+	root := page("Root", nil, false, false)
+	child := page("Child", root, false, false)
+	grandchild := page("Grandchild", child, false, false)
+	restricted := page("Restricted", root, true, false)
+	underRestricted := page("Under restricted", restricted, false, false)
+	sharedInRestricted := page("Shared in restricted", restricted, false, false)
+	grant(sharedInRestricted, models.EditPermission, nil)
+	underShared := page("Under shared", sharedInRestricted, false, false)
+	cutOff := page("Cut off", root, false, false)
+	grant(cutOff, models.NoPermission, nil)
+	underCutOff := page("Under cut off", cutOff, false, false)
+	groupShared := page("Group shared", root, false, false)
+	mustCreate(t, db, &models.GroupPermission{GroupID: group.ID, DocumentID: groupShared.ID, Level: models.EditPermission})
+	userOverridesGroup := page("User overrides group", root, false, false)
+	mustCreate(t, db, &models.GroupPermission{GroupID: group.ID, DocumentID: userOverridesGroup.ID, Level: models.AdminPermission})
+	grant(userOverridesGroup, models.ViewPermission, nil)
+	expired := page("Expired", restricted, false, false)
+	grant(expired, models.AdminPermission, timePtr(time.Now().Add(-time.Hour)))
+	public := page("Public", nil, false, true)
+	own := &models.Document{Title: "Own", AuthorID: reader.ID}
+	mustCreate(t, db, own)
+	deleted := page("Deleted", root, false, false)
+	db.Delete(deleted)
+
+	want := map[uint]models.PermissionLevel{
+		root.ID:               models.ViewPermission,
+		child.ID:              models.ViewPermission,
+		grandchild.ID:         models.ViewPermission,
+		restricted.ID:         models.NoPermission,
+		underRestricted.ID:    models.NoPermission,
+		sharedInRestricted.ID: models.EditPermission,
+		underShared.ID:        models.EditPermission,
+		cutOff.ID:             models.NoPermission,
+		underCutOff.ID:        models.NoPermission,
+		groupShared.ID:        models.EditPermission,
+		userOverridesGroup.ID: models.ViewPermission,
+		expired.ID:            models.NoPermission,
+		public.ID:             models.ViewPermission,
+		own.ID:                models.AdminPermission,
+	}
+
+	var documents []models.Document
+	db.Find(&documents)
+	resolver := newAccessResolver(db, reader)
+	for _, document := range documents {
+		if got := resolver.level(document); got.Rank() != want[document.ID].Rank() {
+			t.Errorf("accessResolver level of %q = %s, want %s", document.Title, got, want[document.ID])
+		}
+	}
+
+	for _, level := range []models.PermissionLevel{models.ViewPermission, models.EditPermission, models.AdminPermission} {
+		var ids []uint
+		if err := documentsWithLevel(db, reader, level).Pluck("id", &ids).Error; err != nil {
+			t.Fatalf("documentsWithLevel(%s): %v", level, err)
+		}
+		found := make(map[uint]bool)
+		for _, id := range ids {
+			found[id] = true
+		}
+		for _, document := range documents {
+			if expected := want[document.ID].Includes(level); found[document.ID] != expected {
+				t.Errorf("documentsWithLevel(%s) has %q = %v, want %v", level, document.Title, found[document.ID], expected)
+			}
+		}
+		if found[deleted.ID] {
+			t.Errorf("documentsWithLevel(%s) has a deleted document", level)
+		}
+	}
+}
+
+func TestViewableSpaces(t *testing.T) {
+	db := openTestDB(t)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com", Password: "x"}
+	reader := models.User{Name: "Reader", Email: "reader@example.com", Password: "x"}
+	mustCreate(t, db, &owner, &reader)
+
+	granted := models.Space{Name: "Granted", OwnerID: owner.ID}
+	withPage := models.Space{Name: "With a shared page", OwnerID: owner.ID}
+	hidden := models.Space{Name: "Hidden", OwnerID: owner.ID}
+	owned := models.Space{Name: "Owned", OwnerID: reader.ID}
+	mustCreate(t, db, &granted, &withPage, &hidden, &owned)
+	mustCreate(t, db, &models.SpacePermission{UserID: reader.ID, SpaceID: granted.ID, Level: models.ViewPermission})
+
+	shared := models.Document{Title: "Shared", AuthorID: owner.ID, SpaceID: &withPage.ID, Restricted: true}
+	secret := models.Document{Title: "Secret", AuthorID: owner.ID, SpaceID: &hidden.ID}
+	mustCreate(t, db, &shared, &secret)
+	mustCreate(t, db, &models.Permission{UserID: reader.ID, DocumentID: shared.ID, Level: models.ViewPermission})
+
+	for _, tt := range []struct {
+		space models.Space
+		want  bool
+	}{{granted, true}, {withPage, true}, {hidden, false}, {owned, true}} {
+		if got := canViewSpace(db, tt.space, reader); got != tt.want {
+			t.Errorf("canViewSpace(%q) = %v, want %v", tt.space.Name, got, tt.want)
+		}
+	}
+
+	var count int64
+	viewableSpaces(db, reader).Count(&count)
+	if count != 3 {
+		t.Errorf("viewableSpaces has %d spaces, want 3", count)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
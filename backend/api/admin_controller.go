@@ -0,0 +1,328 @@
+// backend/api/admin_controller.go
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/auth"
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultUserPageSize = 50
+	maxUserPageSize     = 200
+)
+
+// GetUsers lists every user, newest first. It can search names and emails
+// with q, filter by status (active or deactivated) and role, and is paged
+// with page and limit.
+func GetUsers(c *gin.Context) {
+	query := config.DB.Model(&models.User{})
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		query = query.Where("name LIKE ? OR email LIKE ?", "%"+q+"%", "%"+q+"%")
+	}
+	switch c.Query("status") {
+	case "":
+	case "active":
+		query = query.Where("deactivated_at IS NULL")
+	case "deactivated":
+		query = query.Where("deactivated_at IS NOT NULL")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status must be active or deactivated"})
+		return
+	}
+	if role := models.UserRole(c.Query("role")); role != "" {
+		if !role.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be MEMBER or ADMIN"})
+			return
+		}
+		query = query.Where("role = ?", role)
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultUserPageSize)))
+	if err != nil || limit < 1 || limit > maxUserPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be between 1 and " + strconv.Itoa(maxUserPageSize)})
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		return
+	}
+	var users []models.User
+	if err := query.Order("created_at desc").Offset((page - 1) * limit).Limit(limit).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users, "total": total, "page": page, "limit": limit})
+}
+
+// DeactivateUser turns off a user's account and logs them out everywhere.
+// Their documents stay where they are.
+func DeactivateUser(c *gin.Context) {
+	target, ok := loadUserToAdminister(c)
+	if !ok {
+		return
+	}
+	if !target.IsActive() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This user is already deactivated"})
+		return
+	}
+
+	if err := config.DB.Model(&models.User{}).Where("id = ?", target.ID).Update("deactivated_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate user"})
+		return
+	}
+	if err := auth.RevokeUser(target.ID); err != nil {
+		log.Printf("Failed to end sessions of deactivated user %d: %v", target.ID, err)
+	}
+
+	config.DB.First(&target, target.ID)
+	c.JSON(http.StatusOK, target)
+}
+
+// ReactivateUser turns a deactivated account back on
+func ReactivateUser(c *gin.Context) {
+	target, ok := loadUserToAdminister(c)
+	if !ok {
+		return
+	}
+	if target.IsActive() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This user is not deactivated"})
+		return
+	}
+
+	if err := config.DB.Model(&models.User{}).Where("id = ?", target.ID).Update("deactivated_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate user"})
+		return
+	}
+
+	config.DB.First(&target, target.ID)
+	c.JSON(http.StatusOK, target)
+}
+
+// UpdateUserRole makes a user an admin or a member
+func UpdateUserRole(c *gin.Context) {
+	var body struct {
+		Role models.UserRole `json:"role"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if !body.Role.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be MEMBER or ADMIN"})
+		return
+	}
+
+	target, ok := loadUserToAdminister(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Model(&models.User{}).Where("id = ?", target.ID).Update("role", body.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	config.DB.First(&target, target.ID)
+	c.JSON(http.StatusOK, target)
+}
+
+// ForcePasswordReset logs a user out everywhere, revokes their personal
+// access tokens and makes them choose a new password, emailing them a reset
+// link
+func ForcePasswordReset(c *gin.Context) {
+	target, ok := loadUserToAdminister(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Model(&models.User{}).Where("id = ?", target.ID).Update("must_reset_password", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to force password reset"})
+		return
+	}
+	if err := auth.RevokeUser(target.ID); err != nil {
+		log.Printf("Failed to end sessions of user %d after forced password reset: %v", target.ID, err)
+	}
+	if err := auth.RevokePersonalAccessTokens(target.ID); err != nil {
+		log.Printf("Failed to revoke access tokens of user %d after forced password reset: %v", target.ID, err)
+	}
+	if err := sendPasswordResetEmail(target, "An administrator asked you to choose a new password. You cannot log in with your old one any more."); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reset token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset email sent"})
+}
+
+// ReassignDocument gives a document to another user, for example when its
+// author has left. Documents in the trash can be reassigned too, so the new
+// owner can restore them.
+func ReassignDocument(c *gin.Context) {
+	var body struct {
+		Email              string                 `json:"email"`
+		PreviousOwnerLevel models.PermissionLevel `json:"previousOwnerLevel"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if body.PreviousOwnerLevel == "" {
+		body.PreviousOwnerLevel = models.NoPermission
+	}
+	if !isValidUserLevel(body.PreviousOwnerLevel) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidUserLevelMessage})
+		return
+	}
+
+	documentID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	var document models.Document
+	if err := allWorkspacesDB(c).Unscoped().First(&document, documentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	// The new owner has to belong to the document's workspace
+	db := config.DB.WithContext(tenancy.WithWorkspace(c.Request.Context(), document.WorkspaceID))
+	newOwner, err := findWorkspaceMember(db, body.Email)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found in the document's workspace"})
+		return
+	}
+	if !newOwner.IsActive() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This user is deactivated"})
+		return
+	}
+	if newOwner.ID == document.AuthorID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This user already owns the document"})
+		return
+	}
+
+	userCtx, _ := c.Get("user")
+	admin := userCtx.(models.User)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return transferDocument(tx, document, newOwner, body.PreviousOwnerLevel, admin.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reassign document"})
+		return
+	}
+
+	db.Unscoped().Preload("Author").First(&document, document.ID)
+	c.JSON(http.StatusOK, document)
+}
+
+// GetStats returns system-wide counts of users and content
+func GetStats(c *gin.Context) {
+	count := func(query *gorm.DB) int64 {
+		var n int64
+		query.Count(&n)
+		return n
+	}
+	db := allWorkspacesDB(c)
+	users := func() *gorm.DB { return db.Model(&models.User{}) }
+	documents := func() *gorm.DB { return db.Model(&models.Document{}) }
+	since := time.Now().AddDate(0, 0, -30)
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": gin.H{
+			"total":       count(users()),
+			"active":      count(users().Where("deactivated_at IS NULL")),
+			"deactivated": count(users().Where("deactivated_at IS NOT NULL")),
+			"admins":      count(users().Where("role = ?", models.AdminRole)),
+			"unverified":  count(users().Where("email_verified_at IS NULL")),
+		},
+		"documents": gin.H{
+			"total":          count(documents()),
+			"public":         count(documents().Where("is_public = ?", true)),
+			"inTrash":        count(db.Unscoped().Model(&models.Document{}).Where("deleted_at IS NOT NULL")),
+			"createdLast30d": count(documents().Where("created_at > ?", since)),
+			"updatedLast30d": count(documents().Where("updated_at > ?", since)),
+			// Documents whose author can no longer log in
+			"orphaned": count(documents().Where("author_id IN (?)", users().Select("id").Where("deactivated_at IS NOT NULL"))),
+		},
+		"workspaces": count(db.Model(&models.Workspace{})),
+		"versions":   count(db.Model(&models.Version{})),
+		"spaces":     count(db.Model(&models.Space{})),
+		"groups":     count(db.Model(&models.Group{})),
+		"shareLinks": count(db.Model(&models.ShareLink{}).Where("expires_at IS NULL OR expires_at > ?", time.Now())),
+	})
+}
+
+// loadUserToAdminister loads the user named in the URL, writing the error
+// response if they cannot be found. Admins cannot use these endpoints on
+// themselves, so the console always keeps at least one working admin.
+func loadUserToAdminister(c *gin.Context) (models.User, bool) {
+	id, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return models.User{}, false
+	}
+	var target models.User
+	if err := config.DB.First(&target, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return models.User{}, false
+	}
+
+	userCtx, _ := c.Get("user")
+	if admin := userCtx.(models.User); admin.ID == target.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change your own account from the admin console"})
+		return models.User{}, false
+	}
+	return target, true
+}
+
+// UnlockLogin clears the failed login count and lockout of an account, an
+// IP address or both
+func UnlockLogin(c *gin.Context) {
+	var body struct {
+		Email     string `json:"email"`
+		IPAddress string `json:"ipAddress"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	email := strings.TrimSpace(body.Email)
+	ip := strings.TrimSpace(body.IPAddress)
+	if email == "" && ip == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Give an email, an IP address or both"})
+		return
+	}
+
+	if email != "" {
+		if err := auth.UnlockAccount(email); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
+			return
+		}
+	}
+	if ip != "" {
+		if err := auth.UnlockIP(ip); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock IP address"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Login unlocked"})
+}
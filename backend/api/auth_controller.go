@@ -1,14 +1,22 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/Devashish08/frigga-assigment/backend/auth"
 	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/mailer"
 	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 func Register(c *gin.Context) {
@@ -35,12 +43,23 @@ func Register(c *gin.Context) {
 		Password: string(hash),
 	}
 
-	result := config.DB.Create(&user)
-	if result.Error != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		_, err := tenancy.CreatePersonalWorkspace(tx, user)
+		return err
+	})
+	if err != nil {
 		// Check for unique constraint violation (duplicate email)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create user. Email may already be in use."})
 		return
 	}
+
+	if err := sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "User registered successfully",
 		"user": gin.H{
@@ -62,33 +81,365 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	ip := c.ClientIP()
+	if wait := auth.LoginLockedFor(body.Email, ip); wait > 0 {
+		tooManyLoginAttempts(c, wait)
+		return
+	}
+
+	// Unknown emails and wrong passwords get the same response, and take as
+	// long, so the endpoint cannot be used to find out who has an account
 	var user models.User
+	hash := dummyPasswordHash
+	if err := config.DB.First(&user, "email = ?", body.Email).Error; err == nil {
+		hash = []byte(user.Password)
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(body.Password)); err != nil || user.ID == 0 {
+		auth.RecordLoginFailure(body.Email, ip)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+	if !user.IsActive() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been deactivated"})
+		return
+	}
+	if user.MustResetPassword {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You need to choose a new password; check your email for a reset link"})
+		return
+	}
 
-	result := config.DB.First(&user, "email = ?", body.Email)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	// With two-factor authentication the password alone only gets a
+	// challenge, which LoginTwoFactor exchanges for tokens
+	if user.TOTPEnabled {
+		challengeToken, err := auth.IssueChallengeToken(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "Two-factor authentication required",
+			"twoFactorRequired": true,
+			"challengeToken":    challengeToken,
+		})
 		return
 	}
 
-	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password))
+	auth.RecordLoginSuccess(body.Email)
+	tokens, err := auth.StartSession(user, requestClient(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
 		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(time.Hour * 24 * 30).Unix(),
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Login successful",
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
 	})
+}
 
-	tokenString, err := token.SignedString([]byte(config.GetJWTSecret())) // Use our new config function
+// RefreshToken exchanges a refresh token for a new access token and a new
+// refresh token. Presenting a refresh token twice logs that session out.
+func RefreshToken(c *gin.Context) {
+	var body struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	tokens, err := auth.Refresh(body.RefreshToken, requestClient(c))
+	switch {
+	case errors.Is(err, auth.ErrRefreshTokenReused):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token was already used; please log in again"})
+		return
+	case errors.Is(err, auth.ErrInvalidRefreshToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout ends the session a refresh token belongs to
+func Logout(c *gin.Context) {
+	var body struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	err := auth.RevokeRefreshToken(body.RefreshToken)
+	if err != nil && !errors.Is(err, auth.ErrInvalidRefreshToken) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll ends every session of the authenticated user, including the
+// current one
+func LogoutAll(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	if err := auth.RevokeUser(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions"})
+}
+
+// ForgotPassword emails a password reset link. It responds the same way
+// whether or not the email belongs to an account.
+func ForgotPassword(c *gin.Context) {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Where("email = ?", body.Email).First(&user).Error; err == nil {
+		if err := sendPasswordResetEmail(user, "If you did not ask to reset your password you can ignore this email."); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reset token"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If an account exists for that email, a reset link has been sent"})
+}
+
+// ResetPassword sets a new password using a token from a reset email, logs
+// the user out everywhere and revokes their personal access tokens
+func ResetPassword(c *gin.Context) {
+	var body struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if body.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is required"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), 10)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   tokenString,
+	var user models.User
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = auth.ConsumeUserToken(tx, body.Token, models.PasswordResetToken)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"password": string(hash), "must_reset_password": false}
+		// The reset link was opened from the user's inbox, which proves they own it
+		if !user.IsEmailVerified() {
+			updates["email_verified_at"] = time.Now()
+		}
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error
+	})
+	if errors.Is(err, auth.ErrInvalidUserToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset link"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	if err := auth.RevokeUser(user.ID); err != nil {
+		log.Printf("Failed to end sessions of user %d after password reset: %v", user.ID, err)
+	}
+	if err := auth.RevokePersonalAccessTokens(user.ID); err != nil {
+		log.Printf("Failed to revoke access tokens of user %d after password reset: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// VerifyEmail confirms a user's email address using a token from a
+// verification email
+func VerifyEmail(c *gin.Context) {
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := auth.ConsumeUserToken(tx, body.Token, models.EmailVerificationToken)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Update("email_verified_at", time.Now()).Error
+	})
+	if errors.Is(err, auth.ErrInvalidUserToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerificationEmail sends the authenticated user a new verification link
+func ResendVerificationEmail(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	if user.IsEmailVerified() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Your email is already verified"})
+		return
+	}
+
+	if err := sendVerificationEmail(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
+)
+
+// sendPasswordResetEmail emails a user a link to choose a new password, with
+// a closing note explaining why they got it
+func sendPasswordResetEmail(user models.User, note string) error {
+	token, err := auth.CreateUserToken(user.ID, models.PasswordResetToken, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+	mailer.SendAsync(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in one hour.\n\n%s/reset-password?token=%s\n\n%s\n",
+			user.Name, config.GetAppURL(), url.QueryEscape(token), note),
+	})
+	return nil
+}
+
+// sendVerificationEmail emails a user a link to confirm their address
+func sendVerificationEmail(user models.User) error {
+	token, err := auth.CreateUserToken(user.ID, models.EmailVerificationToken, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	mailer.SendAsync(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in 48 hours.\n\n%s/verify-email?token=%s\n",
+			user.Name, config.GetAppURL(), url.QueryEscape(token)),
 	})
+	return nil
+}
+
+// sessionSummary is a session as shown to its user
+type sessionSummary struct {
+	models.Session
+	Current bool `json:"current"`
+}
+
+// GetSessions lists the devices the authenticated user is logged in on
+func GetSessions(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	sessions, err := auth.ActiveSessions(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+
+	currentID := c.GetUint("session_id")
+	summaries := make([]sessionSummary, 0, len(sessions))
+	for _, session := range sessions {
+		summaries = append(summaries, sessionSummary{Session: session, Current: session.ID == currentID})
+	}
+
+	c.JSON(http.StatusOK, summaries)
+}
+
+// TerminateSession logs the authenticated user out of one of their sessions
+func TerminateSession(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	sessionID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	var session models.Session
+	if err := config.DB.Where("user_id = ? AND revoked_at IS NULL", user.ID).First(&session, sessionID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := auth.RevokeSession(session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to terminate session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session terminated successfully"})
+}
+
+// dummyPasswordHash is compared against when no user has the email given to
+// Login, so that takes as long as checking a real password
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+
+// tooManyLoginAttempts rejects a login while its account or IP address is
+// locked out
+func tooManyLoginAttempts(c *gin.Context, wait time.Duration) {
+	seconds := int(wait.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":      "Too many failed login attempts; try again later",
+		"retryAfter": seconds,
+	})
+}
+
+// requestClient describes the device a request came from
+func requestClient(c *gin.Context) auth.Client {
+	return auth.Client{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
+}
+
+// GetJWKS publishes the public keys tokens are signed with, so other services
+// can verify them without sharing a secret. The same keys sign 2FA challenges
+// and SSO state, so a verifier must check that "iss" is JWT_ISSUER, "aud" is
+// JWT_AUDIENCE and "typ" is "access" before trusting a token.
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": auth.PublicKeys()})
 }
@@ -0,0 +1,60 @@
+// backend/api/collab_controller.go
+package api
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/Devashish08/frigga-assigment/backend/collab"
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		// Non-browser clients don't send an Origin header
+		origin := r.Header.Get("Origin")
+		return origin == "" || slices.Contains(config.GetAllowedOrigins(), origin)
+	},
+}
+
+// CollaborateOnDocument upgrades the request to a websocket and joins the
+// document's live editing session. Users with view access follow along
+// read-only; users with edit access can send operations.
+func CollaborateOnDocument(c *gin.Context) {
+	id, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !canViewDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this document"})
+		return
+	}
+	// The upgrade is a GET, so the read-only check in AuthMiddleware lets
+	// read-scoped tokens through; they may follow along but not edit
+	tokenScope, _ := c.Get("token_scope")
+	scope, _ := tokenScope.(models.TokenScope)
+	canEdit := scope.Includes(models.WriteScope) && canEditDocument(workspaceDB(c), document, user)
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already replied with an error
+		return
+	}
+
+	collab.DefaultHub.Serve(conn, document, user, canEdit)
+}
@@ -2,13 +2,18 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/collab"
+	"github.com/Devashish08/frigga-assigment/backend/diff"
 	"github.com/Devashish08/frigga-assigment/backend/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // backend/api/document_controller.go
@@ -18,15 +23,10 @@ func GetDocuments(c *gin.Context) {
 	userCtx, _ := c.Get("user")
 	user := userCtx.(models.User)
 
-	// Get IDs of documents shared with the user
-	var sharedDocIDs []uint
-	config.DB.Model(&models.Permission{}).Where("user_id = ?", user.ID).Pluck("document_id", &sharedDocIDs)
-
 	var documents []models.Document
 
-	// NEW, MORE COMPLEX QUERY
-	result := config.DB.Preload("Author").
-		Where("author_id = ? OR is_public = ? OR id IN ?", user.ID, true, sharedDocIDs).
+	// Authored, public, shared and inherited documents
+	result := viewableDocuments(workspaceDB(c), user).Preload("Author").
 		Order("updated_at desc").
 		Find(&documents)
 
@@ -48,6 +48,8 @@ func CreateDocument(c *gin.Context) {
 		Title    string `json:"title"`
 		Content  string `json:"content"`
 		IsPublic bool   `json:"isPublic"`
+		SpaceID  *uint  `json:"spaceId"`
+		ParentID *uint  `json:"parentId"`
 	}
 	if err := c.Bind(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
@@ -60,22 +62,31 @@ func CreateDocument(c *gin.Context) {
 		return
 	}
 
+	spaceID, status, err := resolvePageLocation(workspaceDB(c), user, body.ParentID, body.SpaceID)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Create the document
 	document := models.Document{
 		Title:    body.Title,
 		Content:  body.Content,
 		IsPublic: body.IsPublic,
 		AuthorID: user.ID,
+		SpaceID:  spaceID,
+		ParentID: body.ParentID,
+		Position: nextPosition(workspaceDB(c), spaceID, body.ParentID),
 	}
 
-	result := config.DB.Create(&document)
+	result := workspaceDB(c).Create(&document)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create document"})
 		return
 	}
 
 	// Preload the author information to return it in the response
-	config.DB.Preload("Author").First(&document, document.ID)
+	workspaceDB(c).Preload("Author").First(&document, document.ID)
 
 	c.JSON(http.StatusCreated, document)
 }
@@ -84,10 +95,14 @@ func CreateDocument(c *gin.Context) {
 
 // GetDocument retrieves a single document by its ID, checking permissions
 func GetDocument(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
 
 	var document models.Document
-	if err := config.DB.Preload("Author").First(&document, id).Error; err != nil {
+	if err := workspaceDB(c).Preload("Author").First(&document, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
 		return
 	}
@@ -103,44 +118,39 @@ func GetDocument(c *gin.Context) {
 		}
 		user := userCtx.(models.User)
 
-		// Check if the logged-in user is the author
-		if document.AuthorID != user.ID {
-			// NEW PERMISSION CHECK
-			var permission models.Permission
-			err := config.DB.Where("document_id = ? AND user_id = ?", document.ID, user.ID).First(&permission).Error
-			if err != nil { // No permission found
-				c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this document"})
-				return
-			}
+		if !canViewDocument(workspaceDB(c), document, user) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":            "You do not have permission to view this document",
+				"canRequestAccess": true,
+			})
+			return
 		}
 	}
 
 	// If the document is public, or the user is the author, they can view it.
+	c.Header("ETag", revisionETag(document.Revision))
 	c.JSON(http.StatusOK, document)
 }
 
-// UpdateDocument updates an existing document
+// UpdateDocument updates an existing document. Live edits are persisted
+// with a new revision, so a client in the document's live session takes its
+// If-Match from the session's "saved" messages rather than its last save.
 func UpdateDocument(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
 	userCtx, _ := c.Get("user")
 	user := userCtx.(models.User)
 
 	var document models.Document
-	if err := config.DB.First(&document, id).Error; err != nil {
+	if err := workspaceDB(c).First(&document, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
 		return
 	}
 
-	// NEW PERMISSION CHECK
-	isAuthor := document.AuthorID == user.ID
-	var hasEditPermission bool
-	if !isAuthor {
-		var permission models.Permission
-		err := config.DB.Where("document_id = ? AND user_id = ? AND level = ?", document.ID, user.ID, models.EditPermission).First(&permission).Error
-		hasEditPermission = err == nil
-	}
-
-	if !isAuthor && !hasEditPermission {
+	if !canEditDocument(workspaceDB(c), document, user) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to edit this document"})
 		return
 	}
@@ -149,12 +159,29 @@ func UpdateDocument(c *gin.Context) {
 		Title    string `json:"title"`
 		Content  string `json:"content"`
 		IsPublic bool   `json:"isPublic"`
+		Revision *uint  `json:"revision"`
 	}
 	if err := c.Bind(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
 		return
 	}
 
+	// Making a document public or private is a setting only admins can change
+	if body.IsPublic != document.IsPublic && !canManageDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to change the visibility of this document"})
+		return
+	}
+
+	// The client must tell us which revision its changes are based on
+	baseRevision, ok := parseRevisionETag(c.GetHeader("If-Match"))
+	if !ok && body.Revision != nil {
+		baseRevision, ok = *body.Revision, true
+	}
+	if !ok {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "An If-Match header or revision is required"})
+		return
+	}
+
 	// --- NEW: Versioning Logic ---
 	// Create a version of the document *before* it's updated.
 	// This captures the state that is being changed.
@@ -164,14 +191,55 @@ func UpdateDocument(c *gin.Context) {
 		Content:    document.Content,
 		AuthorID:   user.ID, // The user making the current change is the author of this version
 	}
-	config.DB.Create(&version)
 	// --- End of Versioning Logic ---
 
-	// Update the document fields
-	document.Title = body.Title
-	document.Content = body.Content
-	document.IsPublic = body.IsPublic
-	config.DB.Save(&document)
+	// Only update the document if nobody else changed it in the meantime.
+	// Live edits are persisted first, so they count as such a change.
+	var conflict bool
+	err := collab.DefaultHub.Update(document.ID, func() (string, bool, error) {
+		err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+			// The live session may just have stored newer content
+			var current models.Document
+			if err := tx.First(&current, document.ID).Error; err != nil {
+				return err
+			}
+			version.Title, version.Content = current.Title, current.Content
+
+			result := tx.Model(&models.Document{}).
+				Where("id = ? AND revision = ?", document.ID, baseRevision).
+				Updates(map[string]interface{}{
+					"title":     body.Title,
+					"content":   body.Content,
+					"is_public": body.IsPublic,
+					"revision":  gorm.Expr("revision + 1"),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				conflict = true
+				return nil
+			}
+			return tx.Create(&version).Error
+		})
+		return body.Content, err == nil && !conflict, err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update document"})
+		return
+	}
+
+	workspaceDB(c).Preload("Author").First(&document, document.ID)
+
+	if conflict {
+		c.Header("ETag", revisionETag(document.Revision))
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "The document was changed by someone else",
+			"document": document,
+			"diff":     diff.Documents(document.Title, document.Content, body.Title, body.Content),
+		})
+		return
+	}
 
 	// --- NEW: Auto-sharing logic ---
 	// Find all mentions in the format <span data-type="mention" data-id="USER_ID">
@@ -191,22 +259,47 @@ func UpdateDocument(c *gin.Context) {
 		}
 	}
 
-	// Grant VIEW permission to each mentioned user
+	// Grant VIEW permission to each mentioned user, unless the editor has not
+	// verified their email and so cannot share yet
+	if !user.IsEmailVerified() {
+		mentionedUserIDs = nil
+	}
 	for userID := range mentionedUserIDs {
+		// Only members of the workspace can be pulled in by a mention
+		if !isWorkspaceMember(workspaceDB(c), userID) {
+			continue
+		}
 		permission := models.Permission{
-			UserID:     userID,
-			DocumentID: document.ID,
-			Level:      models.ViewPermission,
+			UserID:      userID,
+			DocumentID:  document.ID,
+			Level:       models.ViewPermission,
+			GrantedByID: &user.ID,
 		}
 		// Use a "FirstOrCreate" to avoid creating duplicate permissions
 		// It will only create if a permission for this user/doc combo doesn't exist
-		config.DB.Where(models.Permission{UserID: userID, DocumentID: document.ID}).FirstOrCreate(&permission)
+		workspaceDB(c).Where(models.Permission{UserID: userID, DocumentID: document.ID}).FirstOrCreate(&permission)
 	}
 	// --- End of auto-sharing logic ---
 
+	c.Header("ETag", revisionETag(document.Revision))
 	c.JSON(http.StatusOK, document)
 }
 
+// revisionETag formats a document revision as an ETag
+func revisionETag(revision uint) string {
+	return fmt.Sprintf(`"%d"`, revision)
+}
+
+// parseRevisionETag reads the revision out of an If-Match header
+func parseRevisionETag(header string) (uint, bool) {
+	value := strings.Trim(strings.TrimPrefix(strings.TrimSpace(header), "W/"), `"`)
+	revision, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(revision), true
+}
+
 // SearchDocuments performs a full-text search across accessible documents
 func SearchDocuments(c *gin.Context) {
 	userCtx, _ := c.Get("user")
@@ -218,19 +311,14 @@ func SearchDocuments(c *gin.Context) {
 		return
 	}
 
-	// Get IDs of documents shared with the user
-	var sharedDocIDs []uint
-	config.DB.Model(&models.Permission{}).Where("user_id = ?", user.ID).Pluck("document_id", &sharedDocIDs)
-
 	// Sanitize the search query for LIKE operator
 	searchQuery := "%" + query + "%"
 
 	var documents []models.Document
 	// Build the final query
-	// 1. Check for access permission (author OR public OR shared)
+	// 1. Check for access permission (author, public, shared or inherited)
 	// 2. AND check if title OR content matches the search query
-	result := config.DB.Preload("Author").
-		Where("(author_id = ? OR is_public = ? OR id IN ?)", user.ID, true, sharedDocIDs).
+	result := viewableDocuments(workspaceDB(c), user).Preload("Author").
 		Where("(title LIKE ? OR content LIKE ?)", searchQuery, searchQuery).
 		Order("updated_at desc").
 		Find(&documents)
@@ -245,15 +333,195 @@ func SearchDocuments(c *gin.Context) {
 
 // GetDocumentVersions retrieves all versions for a single document
 func GetDocumentVersions(c *gin.Context) {
-	// For simplicity, we assume the user has permission to view the document
-	// if they are asking for its history. A stricter check could be added.
-	docId := c.Param("id")
+	docId := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, docId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !canViewDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this document"})
+		return
+	}
 
 	var versions []models.Version
-	config.DB.Preload("Author").
-		Where("document_id = ?", docId).
+	result := workspaceDB(c).Preload("Author").
+		Where("document_id = ?", document.ID).
 		Order("created_at desc").
 		Find(&versions)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch versions"})
+		return
+	}
 
 	c.JSON(http.StatusOK, versions)
 }
+
+// DeleteDocument moves a document to its author's trash. The document can be
+// restored until the purge job removes it for good.
+func DeleteDocument(c *gin.Context) {
+	id, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if document.AuthorID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can delete this document"})
+		return
+	}
+
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&document).Update("deleted_by_id", user.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&document).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete document"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document moved to trash"})
+}
+
+// RestoreVersion makes an earlier version the current content of a document.
+// The state being replaced is saved as a new version so the restore can be undone.
+func RestoreVersion(c *gin.Context) {
+	docId := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, docId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !canEditDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to edit this document"})
+		return
+	}
+
+	versionID, ok := parseID(c.Param("versionId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found"})
+		return
+	}
+	var version models.Version
+	if err := workspaceDB(c).Where("document_id = ?", document.ID).First(&version, versionID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found"})
+		return
+	}
+
+	// Live edits are persisted first so the snapshot below includes them
+	err := collab.DefaultHub.Update(document.ID, func() (string, bool, error) {
+		err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+			if err := tx.First(&document, document.ID).Error; err != nil {
+				return err
+			}
+			snapshot := models.Version{
+				DocumentID: document.ID,
+				Title:      document.Title,
+				Content:    document.Content,
+				AuthorID:   user.ID,
+			}
+			if err := tx.Create(&snapshot).Error; err != nil {
+				return err
+			}
+
+			document.Title = version.Title
+			document.Content = version.Content
+			document.Revision++
+			return tx.Save(&document).Error
+		})
+		return document.Content, err == nil, err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore version"})
+		return
+	}
+
+	workspaceDB(c).Preload("Author").First(&document, document.ID)
+
+	c.JSON(http.StatusOK, document)
+}
+
+// revision identifies one side of a diff: either a stored version or the
+// current state of the document
+type revision struct {
+	VersionID *uint     `json:"versionId"`
+	Title     string    `json:"-"`
+	Content   string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// loadRevision resolves a "from"/"to" query value, which is either a version ID
+// or "current"
+func loadRevision(db *gorm.DB, document models.Document, ref string) (revision, bool) {
+	if ref == "" || ref == "current" {
+		return revision{Title: document.Title, Content: document.Content, CreatedAt: document.UpdatedAt}, true
+	}
+
+	versionID, ok := parseID(ref)
+	if !ok {
+		return revision{}, false
+	}
+	var version models.Version
+	if err := db.Where("document_id = ?", document.ID).First(&version, versionID).Error; err != nil {
+		return revision{}, false
+	}
+	return revision{VersionID: &version.ID, Title: version.Title, Content: version.Content, CreatedAt: version.CreatedAt}, true
+}
+
+// GetDocumentDiff returns a structured diff between two revisions of a document.
+// Both revisions default to the current state of the document.
+func GetDocumentDiff(c *gin.Context) {
+	docId := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, docId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !canViewDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this document"})
+		return
+	}
+
+	from, ok := loadRevision(workspaceDB(c), document, c.Query("from"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found: " + c.Query("from")})
+		return
+	}
+	to, ok := loadRevision(workspaceDB(c), document, c.Query("to"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found: " + c.Query("to")})
+		return
+	}
+
+	result := diff.Documents(from.Title, from.Content, to.Title, to.Content)
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":   from,
+		"to":     to,
+		"title":  result.Title,
+		"blocks": result.Blocks,
+		"stats":  result.Stats,
+	})
+}
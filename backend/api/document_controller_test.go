@@ -0,0 +1,63 @@
+// backend/api/document_controller_test.go
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
+	"github.com/gin-gonic/gin"
+)
+
+// serveDocumentRoute calls a handler of the document routes as user, with
+// the request in workspace 1
+func serveDocumentRoute(handler gin.HandlerFunc, user models.User, documentID uint) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil).
+		WithContext(tenancy.WithWorkspace(context.Background(), 1))
+	c.Set("user", user)
+	c.Set("doc_id_as_uint", documentID)
+	handler(c)
+	return recorder
+}
+
+func TestGetDocumentVersions(t *testing.T) {
+	db := openTestDB(t)
+
+	author := models.User{Name: "Author", Email: "author@example.com", Password: "x"}
+	stranger := models.User{Name: "Stranger", Email: "stranger@example.com", Password: "x"}
+	mustCreate(t, db, &author, &stranger)
+	document := models.Document{Title: "Plan", AuthorID: author.ID}
+	trashed := models.Document{Title: "Trashed", AuthorID: author.ID}
+	mustCreate(t, db, &document, &trashed)
+	mustCreate(t, db,
+		&models.Version{DocumentID: document.ID, Title: "Plan", Content: "v1", AuthorID: author.ID},
+		&models.Version{DocumentID: trashed.ID, Title: "Trashed", Content: "v1", AuthorID: author.ID},
+	)
+	db.Delete(&trashed)
+
+	tests := []struct {
+		name       string
+		user       models.User
+		documentID uint
+		want       int
+	}{
+		{"author", author, document.ID, http.StatusOK},
+		{"no access", stranger, document.ID, http.StatusForbidden},
+		{"trashed", author, trashed.ID, http.StatusNotFound},
+		{"unknown", author, 999, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if recorder := serveDocumentRoute(GetDocumentVersions, tt.user, tt.documentID); recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body)
+			}
+		})
+	}
+}
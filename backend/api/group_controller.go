@@ -0,0 +1,321 @@
+// backend/api/group_controller.go
+package api
+
+import (
+	"net/http"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateGroup creates a group with the authenticated user as its first admin
+func CreateGroup(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if body.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+
+	group := models.Group{
+		Name:        body.Name,
+		Description: body.Description,
+		CreatedByID: user.ID,
+	}
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.GroupMember{GroupID: group.ID, UserID: user.ID, IsAdmin: true}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create group. Name may already be in use."})
+		return
+	}
+
+	workspaceDB(c).Preload("Members.User").First(&group, group.ID)
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// GetGroups lists groups, optionally filtered by name with the "q" query parameter
+func GetGroups(c *gin.Context) {
+	query := workspaceDB(c).Order("name")
+	if q := c.Query("q"); q != "" {
+		query = query.Where("name LIKE ?", "%"+q+"%")
+	}
+
+	var groups []models.Group
+	if err := query.Find(&groups).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetGroup retrieves a group along with its members
+func GetGroup(c *gin.Context) {
+	groupID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	var group models.Group
+	if err := workspaceDB(c).Preload("Members.User").First(&group, groupID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// DeleteGroup removes a group, its memberships and every grant made to it
+func DeleteGroup(c *gin.Context) {
+	group, ok := loadGroupAsAdmin(c)
+	if !ok {
+		return
+	}
+
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("group_id = ?", group.ID).Delete(&models.GroupPermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("group_id = ?", group.ID).Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&group).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
+}
+
+// AddGroupMember adds a user to a group, optionally as an admin
+func AddGroupMember(c *gin.Context) {
+	var body struct {
+		Email   string `json:"email"`
+		IsAdmin bool   `json:"isAdmin"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	group, ok := loadGroupAsAdmin(c)
+	if !ok {
+		return
+	}
+
+	member, err := findWorkspaceMember(workspaceDB(c), body.Email)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	membership := models.GroupMember{GroupID: group.ID, UserID: member.ID}
+	result := workspaceDB(c).Where(membership).Assign(map[string]interface{}{"is_admin": body.IsAdmin}).FirstOrCreate(&membership)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+		return
+	}
+
+	workspaceDB(c).Preload("User").First(&membership, membership.ID)
+
+	c.JSON(http.StatusCreated, membership)
+}
+
+// UpdateGroupMember promotes a member to group admin or demotes them
+func UpdateGroupMember(c *gin.Context) {
+	var body struct {
+		IsAdmin bool `json:"isAdmin"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	group, ok := loadGroupAsAdmin(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := parseID(c.Param("userId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	var membership models.GroupMember
+	if err := workspaceDB(c).Where("group_id = ? AND user_id = ?", group.ID, userID).First(&membership).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if membership.IsAdmin && !body.IsAdmin && isLastGroupAdmin(group.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A group needs at least one admin"})
+		return
+	}
+
+	workspaceDB(c).Model(&membership).Update("is_admin", body.IsAdmin)
+	workspaceDB(c).Preload("User").First(&membership, membership.ID)
+
+	c.JSON(http.StatusOK, membership)
+}
+
+// RemoveGroupMember removes a user from a group. Members can always remove
+// themselves; removing others requires being a group admin.
+func RemoveGroupMember(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	groupID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	var group models.Group
+	if err := workspaceDB(c).First(&group, groupID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	userID, ok := parseID(c.Param("userId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	var membership models.GroupMember
+	if err := workspaceDB(c).Where("group_id = ? AND user_id = ?", group.ID, userID).First(&membership).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if membership.UserID != user.ID && !isGroupAdmin(group.ID, user.ID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only group admins can manage members"})
+		return
+	}
+
+	if membership.IsAdmin && isLastGroupAdmin(group.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A group needs at least one admin"})
+		return
+	}
+
+	workspaceDB(c).Unscoped().Delete(&membership)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// AddGroupPermission grants every member of a group access to a document
+func AddGroupPermission(c *gin.Context) {
+	docIdUint := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	currentUser := userCtx.(models.User)
+	if !requireVerifiedEmail(c, currentUser) {
+		return
+	}
+
+	var body struct {
+		GroupID uint                   `json:"groupId"`
+		Level   models.PermissionLevel `json:"level"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if !body.Level.IsGrantable() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidGrantLevelMessage})
+		return
+	}
+
+	var group models.Group
+	if err := workspaceDB(c).First(&group, body.GroupID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, docIdUint).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !canManageDocument(workspaceDB(c), document, currentUser) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to share this document"})
+		return
+	}
+
+	permission := models.GroupPermission{GroupID: group.ID, DocumentID: document.ID}
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(permission).
+			Assign(models.GroupPermission{Level: body.Level, GrantedByID: &currentUser.ID}).
+			FirstOrCreate(&permission).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.PermissionEvent{
+			DocumentID: document.ID,
+			GroupID:    &group.ID,
+			Action:     models.PermissionGranted,
+			Level:      body.Level,
+			ActorID:    &currentUser.ID,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant permission"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Permission granted successfully"})
+}
+
+// loadGroupAsAdmin loads the group in the "id" parameter and checks the
+// authenticated user administers it, replying with an error otherwise
+func loadGroupAsAdmin(c *gin.Context) (models.Group, bool) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var group models.Group
+	groupID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+	if err := workspaceDB(c).First(&group, groupID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+
+	if !isGroupAdmin(group.ID, user.ID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only group admins can manage this group"})
+		return group, false
+	}
+	return group, true
+}
+
+func isGroupAdmin(groupID, userID uint) bool {
+	var count int64
+	config.DB.Model(&models.GroupMember{}).Where("group_id = ? AND user_id = ? AND is_admin = ?", groupID, userID, true).Count(&count)
+	return count > 0
+}
+
+func isLastGroupAdmin(groupID uint) bool {
+	var count int64
+	config.DB.Model(&models.GroupMember{}).Where("group_id = ? AND is_admin = ?", groupID, true).Count(&count)
+	return count <= 1
+}
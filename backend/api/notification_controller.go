@@ -0,0 +1,70 @@
+// backend/api/notification_controller.go
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetNotifications lists the authenticated user's notifications, newest first.
+// ?unread=true leaves out the ones already read.
+func GetNotifications(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	query := config.DB.Where("user_id = ?", user.ID).Order("created_at desc")
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.Notification
+	if err := query.Limit(100).Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkNotificationRead marks one of the user's notifications as read
+func MarkNotificationRead(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	notificationID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	var notification models.Notification
+	if err := config.DB.Where("user_id = ?", user.ID).First(&notification, notificationID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		notification.ReadAt = &now
+		if err := config.DB.Model(&notification).Update("read_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+// notify sends a notification to a user
+func notify(db *gorm.DB, userID uint, notificationType models.NotificationType, message string, documentID *uint) error {
+	return db.Create(&models.Notification{
+		UserID:     userID,
+		Type:       notificationType,
+		Message:    message,
+		DocumentID: documentID,
+	}).Error
+}
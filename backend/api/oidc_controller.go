@@ -0,0 +1,120 @@
+// backend/api/oidc_controller.go
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Devashish08/frigga-assigment/backend/auth"
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/gin-gonic/gin"
+)
+
+// oidcFlowCookie holds the signed sign-in state between the redirect to the
+// identity provider and the callback
+const oidcFlowCookie = "oidc_flow"
+
+// OIDCLogin sends the user to the identity provider to sign in
+func OIDCLogin(c *gin.Context) {
+	if !config.OIDCEnabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	authURL, flow, err := auth.StartOIDCFlow(c.Request.Context())
+	if err != nil {
+		log.Printf("Failed to start single sign-on: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Could not reach the identity provider"})
+		return
+	}
+
+	value, err := auth.SignOIDCFlow(flow)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, value, 600, "/api/auth/oidc", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback is where the identity provider sends the user back to. It
+// signs the user in, creating or linking their account on first use, and
+// hands the tokens to the frontend in the URL fragment.
+func OIDCCallback(c *gin.Context) {
+	if !config.OIDCEnabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	value, _ := c.Cookie(oidcFlowCookie)
+	c.SetCookie(oidcFlowCookie, "", -1, "/api/auth/oidc", "", c.Request.TLS != nil, true)
+
+	if providerError := c.Query("error"); providerError != "" {
+		redirectSSOError(c, providerError)
+		return
+	}
+
+	flow, err := auth.ParseOIDCFlow(value)
+	if err != nil || c.Query("state") != flow.State {
+		redirectSSOError(c, "invalid_state")
+		return
+	}
+
+	identity, err := auth.FinishOIDCFlow(c.Request.Context(), flow, c.Query("code"))
+	if err != nil {
+		log.Printf("Failed to finish single sign-on: %v", err)
+		redirectSSOError(c, "sso_failed")
+		return
+	}
+
+	user, err := auth.ResolveOIDCUser(identity)
+	if errors.Is(err, auth.ErrOIDCEmailTaken) {
+		redirectSSOError(c, "account_exists")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to sign in %s from %s: %v", identity.Subject, identity.Issuer, err)
+		redirectSSOError(c, "sso_failed")
+		return
+	}
+	if !user.IsActive() {
+		redirectSSOError(c, "account_deactivated")
+		return
+	}
+	if user.MustResetPassword {
+		redirectSSOError(c, "password_reset_required")
+		return
+	}
+
+	fragment := url.Values{}
+	if user.TOTPEnabled {
+		challengeToken, err := auth.IssueChallengeToken(user.ID)
+		if err != nil {
+			redirectSSOError(c, "sso_failed")
+			return
+		}
+		fragment.Set("challengeToken", challengeToken)
+	} else {
+		tokens, err := auth.StartSession(user, requestClient(c))
+		if err != nil {
+			redirectSSOError(c, "sso_failed")
+			return
+		}
+		fragment.Set("token", tokens.AccessToken)
+		fragment.Set("refreshToken", tokens.RefreshToken)
+		fragment.Set("expiresIn", strconv.Itoa(tokens.ExpiresIn))
+	}
+
+	// The fragment is never sent to a server, so the tokens stay out of logs
+	c.Redirect(http.StatusFound, config.GetAppURL()+"/auth/callback#"+fragment.Encode())
+}
+
+// redirectSSOError sends the user back to the login page with an error code
+func redirectSSOError(c *gin.Context, code string) {
+	c.Redirect(http.StatusFound, config.GetAppURL()+"/login?error="+url.QueryEscape(code))
+}
@@ -0,0 +1,322 @@
+// backend/api/oidc_controller_test.go
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testProvider is a minimal OpenID Connect provider that signs in whoever it
+// is told to without asking
+type testProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu       sync.Mutex
+	subject  string
+	email    string
+	verified bool
+	nonce    string // overrides the nonce of the sign-in when set
+	codes    map[string]url.Values
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	p := &testProvider{key: key, codes: make(map[string]url.Values)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                p.server.URL,
+			"authorization_endpoint":                p.server.URL + "/authorize",
+			"token_endpoint":                        p.server.URL + "/token",
+			"jwks_uri":                              p.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "test", "alg": "RS256", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// signInAs sets who the provider signs in next
+func (p *testProvider) signInAs(subject, email string, verified bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subject, p.email, p.verified, p.nonce = subject, email, verified, ""
+}
+
+func (p *testProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE is required", http.StatusBadRequest)
+		return
+	}
+
+	code, _ := randomCode()
+	p.mu.Lock()
+	p.codes[code] = query
+	p.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirect.RawQuery = values.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *testProvider) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	authorization, ok := p.codes[r.Form.Get("code")]
+	delete(p.codes, r.Form.Get("code"))
+	verifier := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != authorization.Get("code_challenge") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	nonce := authorization.Get("nonce")
+	if p.nonce != "" {
+		nonce = p.nonce
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.server.URL,
+		"sub":            p.subject,
+		"aud":            authorization.Get("client_id"),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          nonce,
+		"email":          p.email,
+		"email_verified": p.verified,
+		"name":           "SSO user",
+	})
+	idToken.Header["kid"] = "test"
+	signed, _ := idToken.SignedString(p.key)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access", "token_type": "Bearer", "expires_in": 3600, "id_token": signed,
+	})
+}
+
+func randomCode() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b), err
+}
+
+// signInWithOIDC walks a browser through single sign-on and returns where
+// the callback finally sends it. tamper can change the callback URL.
+func signInWithOIDC(t *testing.T, router *gin.Engine, tamper func(*url.URL)) *url.URL {
+	t.Helper()
+
+	login := httptest.NewRecorder()
+	router.ServeHTTP(login, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	if login.Code != http.StatusFound {
+		t.Fatalf("login returned %d: %s", login.Code, login.Body)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	response, err := client.Get(login.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	response.Body.Close()
+	callbackURL, err := url.Parse(response.Header.Get("Location"))
+	if err != nil || response.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned %d to %q", response.StatusCode, response.Header.Get("Location"))
+	}
+	if tamper != nil {
+		tamper(callbackURL)
+	}
+
+	callback := httptest.NewRequest(http.MethodGet, callbackURL.RequestURI(), nil)
+	for _, cookie := range login.Result().Cookies() {
+		callback.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, callback)
+	if recorder.Code != http.StatusFound {
+		t.Fatalf("callback returned %d: %s", recorder.Code, recorder.Body)
+	}
+	location, err := url.Parse(recorder.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("callback redirect: %v", err)
+	}
+	return location
+}
+
+// ssoResult returns the tokens handed to the frontend, or the error code the
+// login page was sent
+func ssoResult(t *testing.T, location *url.URL) (url.Values, string) {
+	t.Helper()
+	switch location.Path {
+	case "/auth/callback":
+		fragment, err := url.ParseQuery(location.Fragment)
+		if err != nil {
+			t.Fatalf("fragment: %v", err)
+		}
+		return fragment, ""
+	case "/login":
+		return nil, location.Query().Get("error")
+	default:
+		t.Fatalf("unexpected redirect to %s", location)
+		return nil, ""
+	}
+}
+
+// The provider is discovered once per process, so every case shares it
+func TestOIDCFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := newTestProvider(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("APP_URL", "http://app.test")
+	t.Setenv("OIDC_ISSUER_URL", provider.server.URL)
+	t.Setenv("OIDC_CLIENT_ID", "frigga")
+	t.Setenv("OIDC_CLIENT_SECRET", "secret")
+	t.Setenv("OIDC_REDIRECT_URL", "http://api.test/api/auth/oidc/callback")
+
+	router := gin.New()
+	router.GET("/api/auth/oidc/login", OIDCLogin)
+	router.GET("/api/auth/oidc/callback", OIDCCallback)
+
+	t.Run("new user", func(t *testing.T) {
+		db := openTestDB(t)
+		provider.signInAs("new", "new@example.com", true)
+
+		tokens, code := ssoResult(t, signInWithOIDC(t, router, nil))
+		if code != "" || tokens.Get("token") == "" || tokens.Get("refreshToken") == "" {
+			t.Fatalf("sign in failed with %q: %v", code, tokens)
+		}
+
+		var user models.User
+		if err := db.Where("email = ?", "new@example.com").First(&user).Error; err != nil {
+			t.Fatalf("user was not created: %v", err)
+		}
+		if !user.IsEmailVerified() {
+			t.Error("email verified by the provider is not verified")
+		}
+		var memberships int64
+		db.Unscoped().Table("workspace_members").Where("user_id = ?", user.ID).Count(&memberships)
+		if memberships != 1 {
+			t.Errorf("new user is a member of %d workspaces, want their own", memberships)
+		}
+
+		// Signing in again finds the same user
+		ssoResult(t, signInWithOIDC(t, router, nil))
+		var count int64
+		db.Model(&models.User{}).Count(&count)
+		if count != 1 {
+			t.Errorf("%d users after signing in twice, want 1", count)
+		}
+	})
+
+	t.Run("links a verified email to an existing account", func(t *testing.T) {
+		db := openTestDB(t)
+		now := time.Now()
+		existing := models.User{Name: "Ada", Email: "ada@example.com", Password: "hash", EmailVerifiedAt: &now}
+		mustCreate(t, db, &existing)
+		provider.signInAs("ada", "ada@example.com", true)
+
+		if _, code := ssoResult(t, signInWithOIDC(t, router, nil)); code != "" {
+			t.Fatalf("sign in failed with %q", code)
+		}
+		var identity models.UserIdentity
+		if err := db.Where("subject = ?", "ada").First(&identity).Error; err != nil || identity.UserID != existing.ID {
+			t.Errorf("identity linked to user %d, want %d (%v)", identity.UserID, existing.ID, err)
+		}
+	})
+
+	t.Run("unverified email of an existing account", func(t *testing.T) {
+		db := openTestDB(t)
+		mustCreate(t, db, &models.User{Name: "Ada", Email: "ada@example.com", Password: "hash"})
+		provider.signInAs("mallory", "ada@example.com", false)
+
+		if _, code := ssoResult(t, signInWithOIDC(t, router, nil)); code != "account_exists" {
+			t.Errorf("error = %q, want account_exists", code)
+		}
+	})
+
+	t.Run("two-factor authentication", func(t *testing.T) {
+		db := openTestDB(t)
+		now := time.Now()
+		mustCreate(t, db, &models.User{Name: "Ada", Email: "ada@example.com", EmailVerifiedAt: &now, TOTPEnabled: true})
+		provider.signInAs("ada", "ada@example.com", true)
+
+		tokens, code := ssoResult(t, signInWithOIDC(t, router, nil))
+		if code != "" || tokens.Get("challengeToken") == "" || tokens.Get("token") != "" {
+			t.Errorf("sign in returned %q, %v, want only a challenge", code, tokens)
+		}
+	})
+
+	t.Run("state mismatch", func(t *testing.T) {
+		openTestDB(t)
+		provider.signInAs("new", "new@example.com", true)
+
+		_, code := ssoResult(t, signInWithOIDC(t, router, func(callback *url.URL) {
+			query := callback.Query()
+			query.Set("state", "forged")
+			callback.RawQuery = query.Encode()
+		}))
+		if code != "invalid_state" {
+			t.Errorf("error = %q, want invalid_state", code)
+		}
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		openTestDB(t)
+		provider.signInAs("new", "new@example.com", true)
+		provider.mu.Lock()
+		provider.nonce = "replayed"
+		provider.mu.Unlock()
+
+		if _, code := ssoResult(t, signInWithOIDC(t, router, nil)); code != "sso_failed" {
+			t.Errorf("error = %q, want sso_failed", code)
+		}
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		openTestDB(t)
+		provider.signInAs("new", "new@example.com", true)
+
+		_, code := ssoResult(t, signInWithOIDC(t, router, func(callback *url.URL) {
+			callback.RawQuery = url.Values{"error": {"access_denied"}}.Encode()
+		}))
+		if code != "access_denied" {
+			t.Errorf("error = %q, want access_denied", code)
+		}
+	})
+}
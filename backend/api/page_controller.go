@@ -0,0 +1,286 @@
+// backend/api/page_controller.go
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// breadcrumb is a single ancestor of a page
+type breadcrumb struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+// MoveDocument moves a page, along with all of its descendants, under another
+// parent page and/or into another space. A null parentId moves the page to the
+// top level of spaceId; a page with a parent always lives in its parent's space.
+func MoveDocument(c *gin.Context) {
+	docId := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var body struct {
+		ParentID *uint `json:"parentId"`
+		SpaceID  *uint `json:"spaceId"`
+		Position *int  `json:"position"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, docId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	// Moving a page changes what it inherits access from, for it and every
+	// page below it, so it takes the same access as changing its sharing
+	if !canManageDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to move this document"})
+		return
+	}
+
+	spaceID, status, err := resolvePageLocation(workspaceDB(c), user, body.ParentID, body.SpaceID)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	if body.ParentID != nil && isSelfOrDescendant(workspaceDB(c), *body.ParentID, document.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A page cannot be moved under itself or one of its descendants"})
+		return
+	}
+
+	position := nextPosition(workspaceDB(c), spaceID, body.ParentID)
+	if body.Position != nil {
+		position = *body.Position
+	}
+
+	err = workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&document).Updates(map[string]interface{}{
+			"parent_id": body.ParentID,
+			"space_id":  spaceID,
+			"position":  position,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// Descendants follow the page into its new space
+		descendants := descendantIDs(tx, document.ID)
+		if len(descendants) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Document{}).Where("id IN ?", descendants).Update("space_id", spaceID).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move document"})
+		return
+	}
+
+	workspaceDB(c).Preload("Author").First(&document, document.ID)
+
+	c.JSON(http.StatusOK, document)
+}
+
+// GetDocumentBreadcrumb returns the space and the ancestors of a page, from
+// the top of the tree down to its parent
+func GetDocumentBreadcrumb(c *gin.Context) {
+	docId := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, docId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !canViewDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this document"})
+		return
+	}
+
+	ancestors := []breadcrumb{}
+	visited := map[uint]bool{document.ID: true}
+	for parentID := document.ParentID; parentID != nil && !visited[*parentID]; {
+		var parent models.Document
+		if err := workspaceDB(c).First(&parent, *parentID).Error; err != nil {
+			break
+		}
+		// Ancestors the user cannot see are skipped rather than revealed
+		if canViewDocument(workspaceDB(c), parent, user) {
+			ancestors = append([]breadcrumb{{ID: parent.ID, Title: parent.Title}}, ancestors...)
+		}
+		visited[parent.ID] = true
+		parentID = parent.ParentID
+	}
+
+	var space *models.Space
+	if document.SpaceID != nil {
+		space = &models.Space{}
+		if err := workspaceDB(c).Preload("Owner").First(space, *document.SpaceID).Error; err != nil {
+			space = nil
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"space": space, "ancestors": ancestors})
+}
+
+// GetDocumentChildren lists the direct children of a page the user can view
+func GetDocumentChildren(c *gin.Context) {
+	docId := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, docId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !canViewDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this document"})
+		return
+	}
+
+	var children []models.Document
+	result := viewableDocuments(workspaceDB(c), user).Preload("Author").
+		Where("parent_id = ?", document.ID).
+		Order("position, title").
+		Find(&children)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve child pages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, children)
+}
+
+// resolvePageLocation validates a new parent page and space for a page and
+// returns the space the page ends up in. Pages with a parent take the
+// parent's space. The user needs edit access to the parent page, or to the
+// space for a top-level page, since the page will inherit from it.
+func resolvePageLocation(db *gorm.DB, user models.User, parentID, spaceID *uint) (*uint, int, error) {
+	if parentID != nil {
+		var parent models.Document
+		if err := db.First(&parent, *parentID).Error; err != nil {
+			return nil, http.StatusNotFound, errors.New("Parent page not found")
+		}
+		if !canEditDocument(db, parent, user) {
+			return nil, http.StatusForbidden, errors.New("You are not authorized to edit the parent page")
+		}
+		return parent.SpaceID, 0, nil
+	}
+
+	if spaceID != nil {
+		var space models.Space
+		if err := db.First(&space, *spaceID).Error; err != nil {
+			return nil, http.StatusNotFound, errors.New("Space not found")
+		}
+		if !canEditSpace(db, space, user) {
+			return nil, http.StatusForbidden, errors.New("You are not authorized to add pages to this space")
+		}
+	}
+	return spaceID, 0, nil
+}
+
+// isSelfOrDescendant reports whether candidate is the page itself or lies below it
+func isSelfOrDescendant(db *gorm.DB, candidate, pageID uint) bool {
+	visited := make(map[uint]bool)
+	for id := &candidate; id != nil && !visited[*id]; {
+		if *id == pageID {
+			return true
+		}
+		visited[*id] = true
+
+		// Trashed pages still hold their place in the tree
+		var page models.Document
+		if err := db.Unscoped().Select("id", "parent_id").First(&page, *id).Error; err != nil {
+			return false
+		}
+		id = page.ParentID
+	}
+	return false
+}
+
+// descendantIDs returns the IDs of every page below the given page
+func descendantIDs(db *gorm.DB, pageID uint) []uint {
+	var ids []uint
+	visited := map[uint]bool{pageID: true}
+	level := []uint{pageID}
+	for len(level) > 0 {
+		var children []uint
+		db.Unscoped().Model(&models.Document{}).Where("parent_id IN ?", level).Pluck("id", &children)
+
+		level = level[:0]
+		for _, id := range children {
+			if !visited[id] {
+				visited[id] = true
+				ids = append(ids, id)
+				level = append(level, id)
+			}
+		}
+	}
+	return ids
+}
+
+// nextPosition returns the position after the last sibling at a location in the tree
+func nextPosition(db *gorm.DB, spaceID, parentID *uint) int {
+	query := db.Model(&models.Document{})
+	if parentID != nil {
+		query = query.Where("parent_id = ?", *parentID)
+	} else if spaceID != nil {
+		query = query.Where("parent_id IS NULL AND space_id = ?", *spaceID)
+	} else {
+		return 0
+	}
+
+	var last *int
+	query.Select("MAX(position)").Scan(&last)
+	if last == nil {
+		return 0
+	}
+	return *last + 1
+}
+
+// SetDocumentRestriction turns permission inheritance for a page on or off.
+// A restricted page is only accessible through grants made on the page itself.
+func SetDocumentRestriction(c *gin.Context) {
+	docId := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var body struct {
+		Restricted bool `json:"restricted"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, docId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !canManageDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to change the restrictions of this document"})
+		return
+	}
+
+	if err := workspaceDB(c).Model(&document).Update("restricted", body.Restricted).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update document"})
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
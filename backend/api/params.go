@@ -0,0 +1,15 @@
+// backend/api/params.go
+package api
+
+import "strconv"
+
+// parseID parses a numeric ID from a route parameter or query string.
+// IDs must be parsed before they reach GORM: First(&x, "1 OR 1=1") and
+// Delete(&x, ...) treat a string as raw SQL rather than a primary key.
+func parseID(value string) (uint, bool) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -2,11 +2,13 @@
 package api
 
 import (
+	"math"
 	"net/http"
+	"time"
 
-	"github.com/Devashish08/frigga-assigment/backend/config"
 	"github.com/Devashish08/frigga-assigment/backend/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // SearchUsers finds users to share with (excluding the current user)
@@ -21,32 +23,397 @@ func SearchUsers(c *gin.Context) {
 	}
 
 	var users []models.User
-	config.DB.Where("email LIKE ? AND id != ?", "%"+query+"%", currentUser.ID).Select("id", "name", "email").Limit(10).Find(&users)
+	db := workspaceDB(c)
+	db.Where("email LIKE ? AND id != ? AND id IN (?)", "%"+query+"%", currentUser.ID, workspaceMemberIDs(db)).Select("id", "name", "email").Limit(10).Find(&users)
 	c.JSON(http.StatusOK, users)
 }
 
-// GetPermissionsForDocument returns all permissions for a document
+// grantee is one entry of a document's sharing list: a user or a group
+// together with their level and who granted it when
+type grantee struct {
+	ID        uint                   `json:"id"`
+	Type      string                 `json:"type"`
+	UserID    *uint                  `json:"userId,omitempty"`
+	GroupID   *uint                  `json:"groupId,omitempty"`
+	Name      string                 `json:"name"`
+	Email     string                 `json:"email,omitempty"`
+	Level     models.PermissionLevel `json:"level"`
+	GrantedBy *userSummary           `json:"grantedBy"`
+	GrantedAt time.Time              `json:"grantedAt"`
+
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	ExpiresInDays *int       `json:"expiresInDays,omitempty"`
+}
+
+// userSummary is the public part of a user
+type userSummary struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func summarizeUser(user *models.User) *userSummary {
+	if user == nil || user.ID == 0 {
+		return nil
+	}
+	return &userSummary{ID: user.ID, Name: user.Name, Email: user.Email}
+}
+
+// GetPermissionsForDocument returns who has access to a document, for the sharing dialog
 func GetPermissionsForDocument(c *gin.Context) {
-	// This would be used to show who has access in the sharing modal
-	// For brevity in this assignment, we can skip implementing the full logic for this one if needed.
-	c.JSON(http.StatusOK, gin.H{"message": "Endpoint not fully implemented for brevity."})
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+
+	var permissions []models.Permission
+	workspaceDB(c).Preload("User").Preload("GrantedBy").
+		Where("document_id = ? AND (expires_at IS NULL OR expires_at > ?)", document.ID, time.Now()).
+		Order("created_at").
+		Find(&permissions)
+
+	var groupPermissions []models.GroupPermission
+	workspaceDB(c).Preload("Group").Preload("GrantedBy").Where("document_id = ?", document.ID).Order("created_at").Find(&groupPermissions)
+
+	grantees := make([]grantee, 0, len(permissions)+len(groupPermissions))
+	for _, permission := range permissions {
+		grantees = append(grantees, grantee{
+			ID:        permission.ID,
+			Type:      "user",
+			UserID:    &permission.UserID,
+			Name:      permission.User.Name,
+			Email:     permission.User.Email,
+			Level:     permission.Level,
+			GrantedBy: summarizeUser(permission.GrantedBy),
+			GrantedAt: permission.CreatedAt,
+
+			ExpiresAt:     permission.ExpiresAt,
+			ExpiresInDays: daysUntil(permission.ExpiresAt),
+		})
+	}
+	for _, permission := range groupPermissions {
+		grantees = append(grantees, grantee{
+			ID:        permission.ID,
+			Type:      "group",
+			GroupID:   &permission.GroupID,
+			Name:      permission.Group.Name,
+			Level:     permission.Level,
+			GrantedBy: summarizeUser(permission.GrantedBy),
+			GrantedAt: permission.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"owner":       summarizeUser(&document.Author),
+		"restricted":  document.Restricted,
+		"permissions": grantees,
+	})
+}
+
+// UpdatePermission changes the level or expiry of a user's access to a
+// document. Fields left out are unchanged; neverExpires removes the expiry.
+func UpdatePermission(c *gin.Context) {
+	var body struct {
+		Level        models.PermissionLevel `json:"level"`
+		ExpiresAt    *time.Time             `json:"expiresAt"`
+		NeverExpires bool                   `json:"neverExpires"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if body.Level != "" && !isValidUserLevel(body.Level) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidUserLevelMessage})
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be in the future"})
+		return
+	}
+
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+	userCtx, _ := c.Get("user")
+	currentUser := userCtx.(models.User)
+
+	permissionID, ok := parseID(c.Param("permissionId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+		return
+	}
+	var permission models.Permission
+	if err := workspaceDB(c).Where("document_id = ?", document.ID).First(&permission, permissionID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if body.Level != "" {
+		updates["level"] = body.Level
+		permission.Level = body.Level
+	}
+	if body.ExpiresAt != nil {
+		updates["expires_at"] = body.ExpiresAt
+		permission.ExpiresAt = body.ExpiresAt
+	} else if body.NeverExpires {
+		updates["expires_at"] = nil
+		permission.ExpiresAt = nil
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
+		return
+	}
+
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Permission{}).Where("id = ?", permission.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PermissionEvent{
+			DocumentID: document.ID,
+			UserID:     &permission.UserID,
+			Action:     models.PermissionUpdated,
+			Level:      permission.Level,
+			ExpiresAt:  permission.ExpiresAt,
+			ActorID:    &currentUser.ID,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update permission"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permission updated successfully"})
+}
+
+// RevokePermission removes a user's access to a document
+func RevokePermission(c *gin.Context) {
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+	userCtx, _ := c.Get("user")
+	currentUser := userCtx.(models.User)
+
+	permissionID, ok := parseID(c.Param("permissionId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+		return
+	}
+	var permission models.Permission
+	if err := workspaceDB(c).Where("document_id = ?", document.ID).First(&permission, permissionID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+		return
+	}
+
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&models.Permission{}, permission.ID).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PermissionEvent{
+			DocumentID: document.ID,
+			UserID:     &permission.UserID,
+			Action:     models.PermissionRevoked,
+			Level:      permission.Level,
+			ActorID:    &currentUser.ID,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke permission"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permission revoked successfully"})
+}
+
+// UpdateGroupPermission changes the level of a group's access to a document
+func UpdateGroupPermission(c *gin.Context) {
+	var body struct {
+		Level models.PermissionLevel `json:"level"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if !body.Level.IsGrantable() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidGrantLevelMessage})
+		return
+	}
+
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+	userCtx, _ := c.Get("user")
+	currentUser := userCtx.(models.User)
+
+	permissionID, ok := parseID(c.Param("permissionId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+		return
+	}
+	var permission models.GroupPermission
+	if err := workspaceDB(c).Where("document_id = ?", document.ID).First(&permission, permissionID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+		return
+	}
+
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.GroupPermission{}).Where("id = ?", permission.ID).Update("level", body.Level).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PermissionEvent{
+			DocumentID: document.ID,
+			GroupID:    &permission.GroupID,
+			Action:     models.PermissionUpdated,
+			Level:      body.Level,
+			ActorID:    &currentUser.ID,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update permission"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permission updated successfully"})
+}
+
+// RevokeGroupPermission removes a group's access to a document
+func RevokeGroupPermission(c *gin.Context) {
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+	userCtx, _ := c.Get("user")
+	currentUser := userCtx.(models.User)
+
+	permissionID, ok := parseID(c.Param("permissionId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+		return
+	}
+	var permission models.GroupPermission
+	if err := workspaceDB(c).Where("document_id = ?", document.ID).First(&permission, permissionID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+		return
+	}
+
+	err := workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&models.GroupPermission{}, permission.ID).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PermissionEvent{
+			DocumentID: document.ID,
+			GroupID:    &permission.GroupID,
+			Action:     models.PermissionRevoked,
+			Level:      permission.Level,
+			ActorID:    &currentUser.ID,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke permission"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permission revoked successfully"})
+}
+
+// GetPermissionHistory lists the changes made to who can access a document,
+// including grants that expired
+func GetPermissionHistory(c *gin.Context) {
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+
+	var events []models.PermissionEvent
+	workspaceDB(c).Preload("User").Preload("Group").Preload("Actor").
+		Where("document_id = ?", document.ID).
+		Order("created_at desc").
+		Find(&events)
+
+	c.JSON(http.StatusOK, events)
+}
+
+// daysUntil returns the number of days, rounded up, until a point in time
+func daysUntil(t *time.Time) *int {
+	if t == nil {
+		return nil
+	}
+	days := int(math.Ceil(time.Until(*t).Hours() / 24))
+	return &days
+}
+
+const (
+	invalidUserLevelMessage  = "Level must be one of VIEW, COMMENT, EDIT, ADMIN or NONE"
+	invalidGrantLevelMessage = "Level must be one of VIEW, COMMENT, EDIT or ADMIN"
+)
+
+// isValidUserLevel reports whether a level can be granted to a user on a
+// document. NONE is allowed there to block access inherited from a parent
+// page or space.
+func isValidUserLevel(level models.PermissionLevel) bool {
+	return level.IsGrantable() || level == models.NoPermission
+}
+
+// requireVerifiedEmail stops users who have not confirmed their email address
+// from giving other people access to documents
+func requireVerifiedEmail(c *gin.Context, user models.User) bool {
+	if !user.IsEmailVerified() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address before sharing documents"})
+		return false
+	}
+	return true
+}
+
+// loadDocumentToManage loads the document of the route and checks the
+// authenticated user may manage its sharing, replying with an error otherwise
+func loadDocumentToManage(c *gin.Context) (models.Document, bool) {
+	docIdUint := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	currentUser := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).Preload("Author").First(&document, docIdUint).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return document, false
+	}
+
+	if !canManageDocument(workspaceDB(c), document, currentUser) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to manage access to this document"})
+		return document, false
+	}
+	return document, true
 }
 
 // AddPermission grants a user access to a document
 func AddPermission(c *gin.Context) {
 	docIdUint := c.MustGet("doc_id_as_uint").(uint)
 	var body struct {
-		Email string                 `json:"email"`
-		Level models.PermissionLevel `json:"level"`
+		Email     string                 `json:"email"`
+		Level     models.PermissionLevel `json:"level"`
+		ExpiresAt *time.Time             `json:"expiresAt"`
 	}
 	if err := c.Bind(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
 		return
 	}
 
+	if !isValidUserLevel(body.Level) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidUserLevelMessage})
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be in the future"})
+		return
+	}
+
 	// Find user by email
-	var userToShareWith models.User
-	if err := config.DB.Where("email = ?", body.Email).First(&userToShareWith).Error; err != nil {
+	userToShareWith, err := findWorkspaceMember(workspaceDB(c), body.Email)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
@@ -54,15 +421,18 @@ func AddPermission(c *gin.Context) {
 	// Check if document exists and if current user is the author
 	userCtx, _ := c.Get("user")
 	currentUser := userCtx.(models.User)
+	if !requireVerifiedEmail(c, currentUser) {
+		return
+	}
 
 	var document models.Document
-	if err := config.DB.First(&document, docIdUint).Error; err != nil {
+	if err := workspaceDB(c).First(&document, docIdUint).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
 		return
 	}
 
-	if document.AuthorID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can share this document"})
+	if !canManageDocument(workspaceDB(c), document, currentUser) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to share this document"})
 		return
 	}
 
@@ -70,10 +440,129 @@ func AddPermission(c *gin.Context) {
 	permission := models.Permission{
 		UserID:     userToShareWith.ID,
 		DocumentID: docIdUint,
-		Level:      body.Level,
 	}
-	// GORM's Save will do an "upsert" if the unique key exists
-	config.DB.Save(&permission)
+	err = workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(permission).
+			Assign(map[string]interface{}{
+				"level":         body.Level,
+				"granted_by_id": currentUser.ID,
+				"expires_at":    body.ExpiresAt,
+			}).
+			FirstOrCreate(&permission).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.PermissionEvent{
+			DocumentID: docIdUint,
+			UserID:     &userToShareWith.ID,
+			Action:     models.PermissionGranted,
+			Level:      body.Level,
+			ExpiresAt:  body.ExpiresAt,
+			ActorID:    &currentUser.ID,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant permission"})
+		return
+	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Permission granted successfully"})
 }
+
+// TransferOwnership makes another user the author of a document, for example
+// when its author leaves. The previous author keeps ADMIN access unless
+// previousOwnerLevel says otherwise ("NONE" removes their access). Only the
+// author or an admin of the workspace or system may transfer a document;
+// ADMIN grantees can share it but not take it over.
+func TransferOwnership(c *gin.Context) {
+	var body struct {
+		Email              string                 `json:"email"`
+		PreviousOwnerLevel models.PermissionLevel `json:"previousOwnerLevel"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if body.PreviousOwnerLevel == "" {
+		body.PreviousOwnerLevel = models.AdminPermission
+	}
+	if !isValidUserLevel(body.PreviousOwnerLevel) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidUserLevelMessage})
+		return
+	}
+
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+	userCtx, _ := c.Get("user")
+	currentUser := userCtx.(models.User)
+	workspaceRole, _ := c.Get("workspace_role")
+	if document.AuthorID != currentUser.ID && !currentUser.IsAdmin() && workspaceRole != models.WorkspaceAdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author or an admin can transfer ownership"})
+		return
+	}
+	if !requireVerifiedEmail(c, currentUser) {
+		return
+	}
+
+	newOwner, err := findWorkspaceMember(workspaceDB(c), body.Email)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if newOwner.ID == document.AuthorID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This user already owns the document"})
+		return
+	}
+
+	err = workspaceDB(c).Transaction(func(tx *gorm.DB) error {
+		return transferDocument(tx, document, newOwner, body.PreviousOwnerLevel, currentUser.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer ownership"})
+		return
+	}
+
+	workspaceDB(c).Preload("Author").First(&document, document.ID)
+
+	c.JSON(http.StatusOK, document)
+}
+
+// transferDocument makes a user the author of a document. The previous
+// author keeps a grant at previousOwnerLevel, or none for NoPermission.
+func transferDocument(tx *gorm.DB, document models.Document, newOwner models.User, previousOwnerLevel models.PermissionLevel, actorID uint) error {
+	previousOwnerID := document.AuthorID
+	if err := tx.Unscoped().Model(&models.Document{}).Where("id = ?", document.ID).Update("author_id", newOwner.ID).Error; err != nil {
+		return err
+	}
+
+	// The new owner no longer needs a grant of their own
+	if err := tx.Unscoped().Where("document_id = ? AND user_id = ?", document.ID, newOwner.ID).Delete(&models.Permission{}).Error; err != nil {
+		return err
+	}
+
+	previous := models.Permission{UserID: previousOwnerID, DocumentID: document.ID}
+	if previousOwnerLevel == models.NoPermission {
+		return tx.Unscoped().Where(previous).Delete(&models.Permission{}).Error
+	}
+	err := tx.Where(previous).
+		Assign(map[string]interface{}{
+			"level":         previousOwnerLevel,
+			"granted_by_id": actorID,
+			"expires_at":    nil,
+		}).
+		FirstOrCreate(&previous).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.PermissionEvent{
+		DocumentID: document.ID,
+		UserID:     &previousOwnerID,
+		Action:     models.PermissionGranted,
+		Level:      previousOwnerLevel,
+		ActorID:    &actorID,
+	}).Error
+}
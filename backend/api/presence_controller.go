@@ -0,0 +1,61 @@
+// backend/api/presence_controller.go
+package api
+
+import (
+	"net/http"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/presence"
+	"github.com/gin-gonic/gin"
+)
+
+// GetDocumentPresence lists the users who currently have a document open
+func GetDocumentPresence(c *gin.Context) {
+	docId := c.MustGet("doc_id_as_uint").(uint)
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, docId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !canViewDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this document"})
+		return
+	}
+
+	c.JSON(http.StatusOK, presence.DefaultTracker.Viewers(document.ID))
+}
+
+// WatchDocumentPresence upgrades the request to a websocket that marks the user
+// as present on the document and streams join, leave and cursor events
+func WatchDocumentPresence(c *gin.Context) {
+	id, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).First(&document, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !canViewDocument(workspaceDB(c), document, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this document"})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already replied with an error
+		return
+	}
+
+	presence.DefaultTracker.Serve(conn, document.ID, user)
+}
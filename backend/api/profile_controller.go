@@ -0,0 +1,490 @@
+// backend/api/profile_controller.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/auth"
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/mailer"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const emailChangeTTL = 24 * time.Hour
+
+// localePattern accepts BCP 47 language tags such as en, pt-BR or zh-Hant-TW
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// GetProfile returns the authenticated user
+func GetProfile(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	c.JSON(http.StatusOK, gin.H{"user": userCtx.(models.User)})
+}
+
+// UpdateProfile changes the authenticated user's name, avatar, timezone or
+// locale. Fields left out of the request are not changed.
+func UpdateProfile(c *gin.Context) {
+	var body struct {
+		Name      *string `json:"name"`
+		AvatarURL *string `json:"avatarUrl"`
+		Timezone  *string `json:"timezone"`
+		Locale    *string `json:"locale"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if body.Name != nil {
+		name := strings.TrimSpace(*body.Name)
+		if name == "" || len(name) > 255 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name must be between 1 and 255 characters"})
+			return
+		}
+		updates["name"] = name
+	}
+	if body.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*body.AvatarURL)
+		if avatarURL != "" {
+			parsed, err := url.Parse(avatarURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || len(avatarURL) > 2048 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Avatar must be an http or https URL"})
+				return
+			}
+		}
+		updates["avatar_url"] = avatarURL
+	}
+	if body.Timezone != nil {
+		timezone := strings.TrimSpace(*body.Timezone)
+		if timezone != "" {
+			if _, err := time.LoadLocation(timezone); err != nil || len(timezone) > 64 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone: " + timezone})
+				return
+			}
+		}
+		updates["timezone"] = timezone
+	}
+	if body.Locale != nil {
+		locale := strings.TrimSpace(*body.Locale)
+		if locale != "" && (!localePattern.MatchString(locale) || len(locale) > 35) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Locale must be a language tag like en or pt-BR"})
+			return
+		}
+		updates["locale"] = locale
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
+		return
+	}
+
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	if err := config.DB.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+
+	config.DB.First(&user, user.ID)
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ChangeEmail starts changing the authenticated user's email address. The
+// new address takes over once the link sent to it is opened.
+func ChangeEmail(c *gin.Context) {
+	var body struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Code     string `json:"code"` // for accounts without a password
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+	if !confirmIdentity(c, user, body.Password, body.Code) {
+		return
+	}
+
+	email := strings.TrimSpace(body.Email)
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email || len(email) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+		return
+	}
+	if strings.EqualFold(email, user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This is already your email address"})
+		return
+	}
+	var count int64
+	config.DB.Model(&models.User{}).Where("email = ?", email).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+		return
+	}
+
+	if err := config.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("pending_email", email).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change email"})
+		return
+	}
+	token, err := auth.CreateUserToken(user.ID, models.EmailChangeToken, emailChangeTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create verification token"})
+		return
+	}
+
+	mailer.SendAsync(mailer.Message{
+		To:      email,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below to start using this address for your account. It expires in 24 hours.\n\n%s/confirm-email?token=%s\n",
+			user.Name, config.GetAppURL(), url.QueryEscape(token)),
+	})
+	// The current address hears about it too, in case the account was taken over
+	mailer.SendAsync(mailer.Message{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to change the email address of your account to %s. If this was not you, reset your password straight away.\n",
+			user.Name, email),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Check your new inbox to confirm the change"})
+}
+
+// ConfirmEmailChange switches a user to their new email address using the
+// token sent to it
+func ConfirmEmailChange(c *gin.Context) {
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := auth.ConsumeUserToken(tx, body.Token, models.EmailChangeToken)
+		if err != nil {
+			return err
+		}
+		if user.PendingEmail == "" {
+			return auth.ErrInvalidUserToken
+		}
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"email":             user.PendingEmail,
+			"pending_email":     "",
+			"email_verified_at": time.Now(),
+		}).Error
+	})
+	if errors.Is(err, auth.ErrInvalidUserToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation link"})
+		return
+	}
+	if err != nil {
+		// Someone else registered the address after the change was asked for
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to change email. It may already be in use."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email changed successfully"})
+}
+
+// ChangePassword sets a new password for the authenticated user, logs them
+// out of their other sessions and revokes their personal access tokens
+func ChangePassword(c *gin.Context) {
+	var body struct {
+		CurrentPassword string `json:"currentPassword"`
+		NewPassword     string `json:"newPassword"`
+		Code            string `json:"code"` // for accounts without a password
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if body.NewPassword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New password is required"})
+		return
+	}
+
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+	if !confirmIdentity(c, user, body.CurrentPassword, body.Code) {
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(body.NewPassword), 10)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+	if err := config.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("password", string(hash)).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+	if err := auth.RevokeOtherSessions(user.ID, c.GetUint("session_id")); err != nil {
+		log.Printf("Failed to end other sessions of user %d after password change: %v", user.ID, err)
+	}
+	if err := auth.RevokePersonalAccessTokens(user.ID); err != nil {
+		log.Printf("Failed to revoke access tokens of user %d after password change: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// DeleteProfile deletes the authenticated user's account. Their documents and
+// spaces are either given to another user or, for documents, moved to the
+// trash. The user row is kept, anonymized, so history still points at it.
+func DeleteProfile(c *gin.Context) {
+	var body struct {
+		Password   string `json:"password"`
+		Code       string `json:"code"`      // for accounts without a password
+		Documents  string `json:"documents"` // "transfer" or "trash"
+		TransferTo string `json:"transferTo"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if body.Documents != "transfer" && body.Documents != "trash" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Documents must be transfer or trash"})
+		return
+	}
+
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+	if !confirmIdentity(c, user, body.Password, body.Code) {
+		return
+	}
+
+	var recipient *models.User
+	if body.TransferTo != "" {
+		if !requireVerifiedEmail(c, user) {
+			return
+		}
+		var found models.User
+		if err := config.DB.Where("email = ?", body.TransferTo).First(&found).Error; err != nil || !found.IsActive() {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if found.ID == user.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Choose someone else to transfer to"})
+			return
+		}
+		recipient = &found
+	}
+	if body.Documents == "transfer" && recipient == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Choose a user to transfer your documents to"})
+		return
+	}
+
+	// The account's content may be spread over several workspaces
+	db := allWorkspacesDB(c)
+
+	// Spaces cannot be trashed, so they always need a new owner
+	var ownedSpaces int64
+	db.Model(&models.Space{}).Where("owner_id = ?", user.ID).Count(&ownedSpaces)
+	if ownedSpaces > 0 && recipient == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Choose a user to transfer your spaces to"})
+		return
+	}
+	if recipient != nil && !sharesContentWorkspaces(db, user, *recipient, body.Documents == "transfer") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The user you transfer to must belong to every workspace you have content in"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if recipient != nil {
+			if err := transferAccountContent(tx, user, *recipient, body.Documents == "transfer"); err != nil {
+				return err
+			}
+		}
+		if body.Documents == "trash" {
+			err := tx.Model(&models.Document{}).Where("author_id = ?", user.ID).Update("deleted_by_id", user.ID).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Where("author_id = ?", user.ID).Delete(&models.Document{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := removeAccountAccess(tx, user); err != nil {
+			return err
+		}
+		return anonymizeUser(tx, user)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	if err := auth.RevokeUser(user.ID); err != nil {
+		log.Printf("Failed to end sessions of deleted user %d: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted"})
+}
+
+// transferAccountContent gives a user's spaces and, if documents is set,
+// their documents to someone else. Documents already in the trash go too, so
+// the new owner can still restore them.
+func transferAccountContent(tx *gorm.DB, user, recipient models.User, documents bool) error {
+	if documents {
+		documentIDs := tx.Unscoped().Model(&models.Document{}).Select("id").Where("author_id = ?", user.ID)
+		// The new owner no longer needs grants of their own
+		err := tx.Unscoped().Where("user_id = ? AND document_id IN (?)", recipient.ID, documentIDs).Delete(&models.Permission{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&models.Document{}).Where("author_id = ?", user.ID).Update("author_id", recipient.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	spaceIDs := tx.Model(&models.Space{}).Select("id").Where("owner_id = ?", user.ID)
+	err := tx.Unscoped().Where("user_id = ? AND space_id IN (?)", recipient.ID, spaceIDs).Delete(&models.SpacePermission{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Space{}).Where("owner_id = ?", user.ID).Update("owner_id", recipient.ID).Error
+}
+
+// sharesContentWorkspaces reports whether the recipient belongs to every
+// workspace holding the user's spaces and, if documents is set, documents
+func sharesContentWorkspaces(db *gorm.DB, user, recipient models.User, documents bool) bool {
+	var workspaceIDs []uint
+	db.Model(&models.Space{}).Where("owner_id = ?", user.ID).Distinct().Pluck("workspace_id", &workspaceIDs)
+	if documents {
+		var documentWorkspaceIDs []uint
+		db.Unscoped().Model(&models.Document{}).Where("author_id = ?", user.ID).Distinct().Pluck("workspace_id", &documentWorkspaceIDs)
+		workspaceIDs = append(workspaceIDs, documentWorkspaceIDs...)
+	}
+	if len(workspaceIDs) == 0 {
+		return true
+	}
+
+	var missing int64
+	db.Model(&models.Workspace{}).
+		Where("id IN ? AND id NOT IN (?)", workspaceIDs, db.Model(&models.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", recipient.ID)).
+		Count(&missing)
+	return missing == 0
+}
+
+// removeAccountAccess drops everything that lets a user into the system or
+// into other people's documents
+func removeAccountAccess(tx *gorm.DB, user models.User) error {
+	if err := removeWorkspaceAccess(tx, user.ID, user.ID); err != nil {
+		return err
+	}
+
+	// Workspaces the user was the only admin of get a new admin too
+	var adminOfWorkspaces []uint
+	tx.Model(&models.WorkspaceMember{}).Where("user_id = ? AND role = ?", user.ID, models.WorkspaceAdminRole).Pluck("workspace_id", &adminOfWorkspaces)
+	for _, workspaceID := range adminOfWorkspaces {
+		var admins int64
+		tx.Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND role = ? AND user_id <> ?", workspaceID, models.WorkspaceAdminRole, user.ID).Count(&admins)
+		if admins > 0 {
+			continue
+		}
+		var successor models.WorkspaceMember
+		if err := tx.Where("workspace_id = ? AND user_id <> ?", workspaceID, user.ID).Order("created_at").First(&successor).Error; err != nil {
+			continue
+		}
+		if err := tx.Model(&models.WorkspaceMember{}).Where("id = ?", successor.ID).Update("role", models.WorkspaceAdminRole).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{
+		&models.Permission{},
+		&models.SpacePermission{},
+		&models.GroupMember{},
+		&models.WorkspaceMember{},
+		&models.Notification{},
+		&models.PersonalAccessToken{},
+		&models.UserIdentity{},
+		&models.RecoveryCode{},
+		&models.UserToken{},
+	} {
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Unscoped().Where("requester_id = ? AND status = ?", user.ID, models.AccessRequestPending).Delete(&models.AccessRequest{}).Error
+}
+
+// anonymizeUser wipes a user's personal details and deactivates them. The row
+// stays so documents, versions and history that mention the user still load.
+func anonymizeUser(tx *gorm.DB, user models.User) error {
+	return tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"name":              "Deleted user",
+		"email":             fmt.Sprintf("deleted-%d@users.invalid", user.ID),
+		"password":          "",
+		"pending_email":     "",
+		"email_verified_at": nil,
+		"avatar_url":        "",
+		"timezone":          "",
+		"locale":            "",
+		"totp_secret":       "",
+		"totp_enabled":      false,
+		"role":              models.MemberRole,
+		"deactivated_at":    time.Now(),
+	}).Error
+}
+
+// confirmIdentity makes sure the authenticated user is who they say they are
+// before a sensitive change, writing the error response if they are not.
+// Users type their password. Accounts created through single sign-on have
+// none, so they give a code from their authenticator app if they use two-
+// factor authentication, or else must have signed in within the last few
+// minutes. Wrong guesses count against the login throttle.
+func confirmIdentity(c *gin.Context, user models.User, password, code string) bool {
+	ip := c.ClientIP()
+	if wait := auth.LoginLockedFor(user.Email, ip); wait > 0 {
+		tooManyLoginAttempts(c, wait)
+		return false
+	}
+
+	switch {
+	case user.Password != "":
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+			auth.RecordLoginFailure(user.Email, ip)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+			return false
+		}
+		return true
+	case user.TOTPEnabled && code != "":
+		if !checkSecondFactor(config.DB, user, code, "") {
+			auth.RecordLoginFailure(user.Email, ip)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+			return false
+		}
+		return true
+	case auth.RecentlySignedIn(c.GetUint("session_id")):
+		return true
+	case user.TOTPEnabled:
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":          "Enter a code from your authenticator app, or sign in again, to confirm this change",
+			"reauthenticate": true,
+		})
+		return false
+	default:
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":          "Sign in again to confirm this change",
+			"reauthenticate": true,
+		})
+		return false
+	}
+}
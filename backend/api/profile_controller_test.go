@@ -0,0 +1,78 @@
+// backend/api/profile_controller_test.go
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/auth"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestConfirmIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := openTestDB(t)
+	previous := auth.Throttle
+	auth.Throttle = auth.NewMemoryAttemptStore()
+	t.Cleanup(func() { auth.Throttle = previous })
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+	key, _ := totp.Generate(totp.GenerateOpts{Issuer: "Frigga", AccountName: "sso-2fa@example.com"})
+	withPassword := models.User{Name: "Password", Email: "password@example.com", Password: string(hash)}
+	ssoOnly := models.User{Name: "SSO", Email: "sso@example.com"}
+	ssoWithTOTP := models.User{Name: "SSO 2FA", Email: "sso-2fa@example.com", TOTPEnabled: true, TOTPSecret: key.Secret()}
+	mustCreate(t, db, &withPassword, &ssoOnly, &ssoWithTOTP)
+
+	fresh := models.Session{UserID: ssoOnly.ID, LastSeenAt: time.Now()}
+	old := models.Session{UserID: ssoOnly.ID, LastSeenAt: time.Now()}
+	old.CreatedAt = time.Now().Add(-time.Hour)
+	mustCreate(t, db, &fresh, &old)
+
+	code, _ := totp.GenerateCode(key.Secret(), time.Now())
+
+	tests := []struct {
+		name      string
+		user      models.User
+		sessionID uint
+		password  string
+		code      string
+		want      int
+	}{
+		{"right password", withPassword, old.ID, "password1", "", http.StatusOK},
+		{"wrong password", withPassword, old.ID, "wrong", "", http.StatusUnauthorized},
+		{"password account ignores a recent sign-in", withPassword, fresh.ID, "", "", http.StatusUnauthorized},
+		{"without a password, signed in just now", ssoOnly, fresh.ID, "", "", http.StatusOK},
+		{"without a password, signed in long ago", ssoOnly, old.ID, "", "", http.StatusForbidden},
+		{"without a password, personal access token", ssoOnly, 0, "", "", http.StatusForbidden},
+		{"wrong authenticator code", ssoWithTOTP, old.ID, "", "000000", http.StatusUnauthorized},
+		{"authenticator code", ssoWithTOTP, old.ID, "", code, http.StatusOK},
+		{"authenticator code used twice", ssoWithTOTP, old.ID, "", code, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.sessionID != 0 {
+				c.Set("session_id", tt.sessionID)
+			}
+
+			// Reload the user so a used code's step is seen
+			var user models.User
+			db.First(&user, tt.user.ID)
+
+			if confirmIdentity(c, user, tt.password, tt.code) {
+				c.Status(http.StatusOK)
+			}
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// backend/api/share_link_controller.go
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/auth"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// publicDocument is the read-only view of a document given to anonymous readers
+type publicDocument struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Author    string    `json:"author"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func toPublicDocument(document models.Document) publicDocument {
+	return publicDocument{
+		ID:        document.ID,
+		Title:     document.Title,
+		Content:   document.Content,
+		Author:    document.Author.Name,
+		UpdatedAt: document.UpdatedAt,
+	}
+}
+
+// shareLinkSummary is a share link as shown to the people managing a document.
+// Token is only set in the response that creates the link.
+type shareLinkSummary struct {
+	ID          uint         `json:"id"`
+	Token       string       `json:"token,omitempty"`
+	HasPassword bool         `json:"hasPassword"`
+	ExpiresAt   *time.Time   `json:"expiresAt"`
+	CreatedBy   *userSummary `json:"createdBy"`
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+func summarizeShareLink(link models.ShareLink) shareLinkSummary {
+	return shareLinkSummary{
+		ID:          link.ID,
+		HasPassword: link.PasswordHash != "",
+		ExpiresAt:   link.ExpiresAt,
+		CreatedBy:   summarizeUser(&link.CreatedBy),
+		CreatedAt:   link.CreatedAt,
+	}
+}
+
+// GetPublicDocument returns a document marked public to anyone, signed in or not
+func GetPublicDocument(c *gin.Context) {
+	id, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	var document models.Document
+	if err := allWorkspacesDB(c).Preload("Author").Where("is_public = ?", true).First(&document, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toPublicDocument(document))
+}
+
+// GetSharedDocument returns the document a share link points to. Links with a
+// password need it in the X-Share-Password header.
+func GetSharedDocument(c *gin.Context) {
+	var link models.ShareLink
+	if err := allWorkspacesDB(c).Where("token_hash = ?", auth.HashToken(c.Param("token"))).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "This link is invalid or has been revoked"})
+		return
+	}
+
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusGone, gin.H{"error": "This link has expired"})
+		return
+	}
+
+	if link.PasswordHash != "" {
+		password := c.GetHeader("X-Share-Password")
+		if password == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "This link is password protected", "passwordRequired": true})
+			return
+		}
+		if bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Incorrect password", "passwordRequired": true})
+			return
+		}
+	}
+
+	var document models.Document
+	// The link works for anyone, whichever workspace the document is in
+	if err := allWorkspacesDB(c).Preload("Author").First(&document, link.DocumentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toPublicDocument(document))
+}
+
+// GetShareLinks lists the active share links of a document
+func GetShareLinks(c *gin.Context) {
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+
+	var links []models.ShareLink
+	workspaceDB(c).Preload("CreatedBy").Where("document_id = ?", document.ID).Order("created_at").Find(&links)
+
+	summaries := make([]shareLinkSummary, 0, len(links))
+	for _, link := range links {
+		summaries = append(summaries, summarizeShareLink(link))
+	}
+
+	c.JSON(http.StatusOK, summaries)
+}
+
+// CreateShareLink creates a view-only link to a document, optionally expiring
+// or protected by a password. The token is returned once and cannot be
+// retrieved again.
+func CreateShareLink(c *gin.Context) {
+	var body struct {
+		ExpiresAt *time.Time `json:"expiresAt"`
+		Password  string     `json:"password"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be in the future"})
+		return
+	}
+
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+	userCtx, _ := c.Get("user")
+	currentUser := userCtx.(models.User)
+	if !requireVerifiedEmail(c, currentUser) {
+		return
+	}
+
+	token, err := auth.NewToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+		return
+	}
+
+	link := models.ShareLink{
+		DocumentID:  document.ID,
+		TokenHash:   auth.HashToken(token),
+		ExpiresAt:   body.ExpiresAt,
+		CreatedByID: currentUser.ID,
+		CreatedBy:   currentUser,
+	}
+	if body.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), 10)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+		link.PasswordHash = string(hash)
+	}
+
+	if err := workspaceDB(c).Omit("CreatedBy").Create(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+		return
+	}
+
+	summary := summarizeShareLink(link)
+	summary.Token = token
+	c.JSON(http.StatusCreated, summary)
+}
+
+// RevokeShareLink stops a share link from working
+func RevokeShareLink(c *gin.Context) {
+	document, ok := loadDocumentToManage(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseID(c.Param("linkId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		return
+	}
+	result := workspaceDB(c).Where("document_id = ?", document.ID).Delete(&models.ShareLink{}, linkID)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share link"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share link revoked successfully"})
+}
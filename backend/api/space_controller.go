@@ -0,0 +1,287 @@
+// backend/api/space_controller.go
+package api
+
+import (
+	"net/http"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// pageNode is a page in a space's page tree
+type pageNode struct {
+	ID       uint        `json:"id"`
+	Title    string      `json:"title"`
+	ParentID *uint       `json:"parentId"`
+	Position int         `json:"position"`
+	Children []*pageNode `json:"children"`
+}
+
+// CreateSpace creates a new space owned by the authenticated user
+func CreateSpace(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if body.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+
+	space := models.Space{
+		Name:        body.Name,
+		Description: body.Description,
+		OwnerID:     user.ID,
+	}
+	if err := workspaceDB(c).Create(&space).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create space"})
+		return
+	}
+
+	workspaceDB(c).Preload("Owner").First(&space, space.ID)
+
+	c.JSON(http.StatusCreated, space)
+}
+
+// GetSpaces lists the spaces the user owns, was granted access to, or can see pages of
+func GetSpaces(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var spaces []models.Space
+	result := viewableSpaces(workspaceDB(c), user).Preload("Owner").
+		Order("name").
+		Find(&spaces)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve spaces"})
+		return
+	}
+
+	c.JSON(http.StatusOK, spaces)
+}
+
+// GetSpace retrieves a single space
+func GetSpace(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	spaceID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+		return
+	}
+	var space models.Space
+	if err := workspaceDB(c).Preload("Owner").First(&space, spaceID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+		return
+	}
+
+	if !canViewSpace(workspaceDB(c), space, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this space"})
+		return
+	}
+
+	c.JSON(http.StatusOK, space)
+}
+
+// GetSpacePermissions lists who has been granted access to a whole space
+func GetSpacePermissions(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	spaceID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+		return
+	}
+	var space models.Space
+	if err := workspaceDB(c).First(&space, spaceID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+		return
+	}
+
+	if !canManageSpace(workspaceDB(c), space, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to manage this space"})
+		return
+	}
+
+	var permissions []models.SpacePermission
+	workspaceDB(c).Preload("User").Where("space_id = ?", space.ID).Find(&permissions)
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// AddSpacePermission grants a user access to every page of a space. Pages
+// inherit it unless they are restricted or have a permission of their own.
+func AddSpacePermission(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+	if !requireVerifiedEmail(c, user) {
+		return
+	}
+
+	var body struct {
+		Email string                 `json:"email"`
+		Level models.PermissionLevel `json:"level"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if !body.Level.IsGrantable() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Level must be one of VIEW, COMMENT, EDIT or ADMIN"})
+		return
+	}
+
+	spaceID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+		return
+	}
+	var space models.Space
+	if err := workspaceDB(c).First(&space, spaceID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+		return
+	}
+
+	if !canManageSpace(workspaceDB(c), space, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to manage this space"})
+		return
+	}
+
+	userToShareWith, err := findWorkspaceMember(workspaceDB(c), body.Email)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	permission := models.SpacePermission{UserID: userToShareWith.ID, SpaceID: space.ID}
+	result := workspaceDB(c).Where(permission).Assign(models.SpacePermission{Level: body.Level}).FirstOrCreate(&permission)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant permission"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Permission granted successfully"})
+}
+
+// RemoveSpacePermission revokes a user's access to a space
+func RemoveSpacePermission(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	spaceID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+		return
+	}
+	var space models.Space
+	if err := workspaceDB(c).First(&space, spaceID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+		return
+	}
+
+	if !canManageSpace(workspaceDB(c), space, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to manage this space"})
+		return
+	}
+
+	userID, ok := parseID(c.Param("userId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+		return
+	}
+	result := workspaceDB(c).Unscoped().Where("space_id = ? AND user_id = ?", space.ID, userID).Delete(&models.SpacePermission{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke permission"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permission revoked successfully"})
+}
+
+// GetSpaceTree returns the whole page tree of a space in one call. Pages the
+// user cannot view are left out; their visible descendants are attached to
+// the closest visible ancestor instead.
+func GetSpaceTree(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	spaceID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+		return
+	}
+	var space models.Space
+	if err := workspaceDB(c).Preload("Owner").First(&space, spaceID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+		return
+	}
+
+	if !canViewSpace(workspaceDB(c), space, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this space"})
+		return
+	}
+
+	// The parent links of every page, to find ancestors of hidden pages
+	var links []models.Document
+	workspaceDB(c).Select("id", "parent_id").Where("space_id = ?", space.ID).Find(&links)
+	parents := make(map[uint]*uint, len(links))
+	for _, link := range links {
+		parents[link.ID] = link.ParentID
+	}
+
+	var pages []models.Document
+	result := viewableDocuments(workspaceDB(c), user).
+		Select("id", "title", "parent_id", "position").
+		Where("space_id = ?", space.ID).
+		Order("position, title").
+		Find(&pages)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pages"})
+		return
+	}
+
+	nodes := make(map[uint]*pageNode, len(pages))
+	for _, page := range pages {
+		nodes[page.ID] = &pageNode{ID: page.ID, Title: page.Title, ParentID: page.ParentID, Position: page.Position, Children: []*pageNode{}}
+	}
+
+	roots := []*pageNode{}
+	for _, page := range pages {
+		node := nodes[page.ID]
+		parent := closestAncestor(page.ID, parents, nodes)
+		if parent == nil {
+			roots = append(roots, node)
+		} else {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"space": space, "pages": roots})
+}
+
+// closestAncestor walks up from a page and returns the first ancestor present in nodes
+func closestAncestor(id uint, parents map[uint]*uint, nodes map[uint]*pageNode) *pageNode {
+	visited := map[uint]bool{id: true}
+	for parentID := parents[id]; parentID != nil && !visited[*parentID]; parentID = parents[*parentID] {
+		if node, ok := nodes[*parentID]; ok {
+			return node
+		}
+		visited[*parentID] = true
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// backend/api/token_controller.go
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/auth"
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPersonalAccessTokens lists the authenticated user's personal access tokens
+func GetPersonalAccessTokens(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var tokens []models.PersonalAccessToken
+	if err := config.DB.Where("user_id = ?", user.ID).Order("created_at desc").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreatePersonalAccessToken makes a named, scoped token for scripts. The
+// token is returned once and cannot be retrieved again.
+func CreatePersonalAccessToken(c *gin.Context) {
+	var body struct {
+		Name      string            `json:"name"`
+		Scope     models.TokenScope `json:"scope"`
+		ExpiresAt *time.Time        `json:"expiresAt"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token name is required"})
+		return
+	}
+	if body.Scope.Rank() == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Scope must be one of read, write or admin"})
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be in the future"})
+		return
+	}
+
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	token, pat, err := auth.CreatePersonalAccessToken(user.ID, body.Name, body.Scope, body.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token":               token,
+		"personalAccessToken": pat,
+	})
+}
+
+// RevokePersonalAccessToken stops one of the user's tokens from working
+func RevokePersonalAccessToken(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	tokenID, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+	result := config.DB.Where("user_id = ?", user.ID).Delete(&models.PersonalAccessToken{}, tokenID)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// trashedDocument is a deleted document along with the time it will be purged
+type trashedDocument struct {
+	models.Document
+	PurgeAt time.Time `json:"purgeAt"`
+}
+
+// GetTrash lists the documents the authenticated user has in their trash
+func GetTrash(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var documents []models.Document
+	result := workspaceDB(c).Unscoped().Preload("Author").
+		Where("deleted_at IS NOT NULL").
+		Where("author_id = ? OR deleted_by_id = ?", user.ID, user.ID).
+		Order("deleted_at desc").
+		Find(&documents)
+
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve trash"})
+		return
+	}
+
+	retention := config.GetTrashRetention()
+	trash := make([]trashedDocument, 0, len(documents))
+	for _, document := range documents {
+		trash = append(trash, trashedDocument{
+			Document: document,
+			PurgeAt:  document.DeletedAt.Time.Add(retention),
+		})
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+// RestoreDocument takes a document out of the trash
+func RestoreDocument(c *gin.Context) {
+	id, ok := parseID(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found in trash"})
+		return
+	}
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var document models.Document
+	if err := workspaceDB(c).Unscoped().Where("deleted_at IS NOT NULL").First(&document, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found in trash"})
+		return
+	}
+
+	isDeleter := document.DeletedByID != nil && *document.DeletedByID == user.ID
+	if document.AuthorID != user.ID && !isDeleter {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to restore this document"})
+		return
+	}
+
+	result := workspaceDB(c).Unscoped().Model(&document).Updates(map[string]interface{}{
+		"deleted_at":    nil,
+		"deleted_by_id": nil,
+	})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore document"})
+		return
+	}
+
+	workspaceDB(c).Preload("Author").First(&document, document.ID)
+
+	c.JSON(http.StatusOK, document)
+}
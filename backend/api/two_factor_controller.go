@@ -0,0 +1,267 @@
+// backend/api/two_factor_controller.go
+package api
+
+import (
+	"net/http"
+
+	"github.com/Devashish08/frigga-assigment/backend/auth"
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// EnrollTwoFactor starts setting up two-factor authentication. It returns a
+// secret and an otpauth:// URI for the user's authenticator app; nothing
+// changes at login until the first code is confirmed.
+func EnrollTwoFactor(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	key, err := auth.NewTOTPKey(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	if err := config.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("totp_secret", key.Secret()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":     key.Secret(),
+		"otpauthUrl": key.URL(),
+	})
+}
+
+// ConfirmTwoFactor finishes enrollment with a code from the authenticator app
+// and returns the user's recovery codes, which are only shown this once
+func ConfirmTwoFactor(c *gin.Context) {
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start enrollment before confirming a code"})
+		return
+	}
+
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, body.Code, 0)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+		}).Error
+		if err != nil {
+			return err
+		}
+		codes, err = auth.GenerateRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": codes,
+	})
+}
+
+// DisableTwoFactor turns two-factor authentication off. It needs a current
+// code or recovery code, and the user's password. Accounts without a password
+// confirm with the code alone, or with a recovery code and a recent sign-in,
+// as confirmIdentity allows.
+func DisableTwoFactor(c *gin.Context) {
+	var body struct {
+		Password     string `json:"password"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recoveryCode"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	ip := c.ClientIP()
+	if wait := auth.LoginLockedFor(user.Email, ip); wait > 0 {
+		tooManyLoginAttempts(c, wait)
+		return
+	}
+	// Without a password, the code checked below is the confirmation
+	if (user.Password != "" || body.Code == "") && !confirmIdentity(c, user, body.Password, "") {
+		return
+	}
+
+	var verified bool
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		verified = checkSecondFactor(tx, user, body.Code, body.RecoveryCode)
+		if !verified {
+			return nil
+		}
+		err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"totp_enabled":   false,
+			"totp_secret":    "",
+			"totp_last_step": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+	if !verified {
+		auth.RecordLoginFailure(user.Email, ip)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a
+// current TOTP code
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	var codes []string
+	var verified bool
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		verified = checkSecondFactor(tx, user, body.Code, "")
+		if !verified {
+			return nil
+		}
+		var err error
+		codes, err = auth.GenerateRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+	if !verified {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// LoginTwoFactor is the second step of logging in for users with two-factor
+// authentication. It takes the challenge token from Login and either a TOTP
+// code or a recovery code.
+func LoginTwoFactor(c *gin.Context) {
+	var body struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recoveryCode"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	userID, err := auth.ParseChallengeToken(body.ChallengeToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge; please log in again"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil || !user.TOTPEnabled || !user.IsActive() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge; please log in again"})
+		return
+	}
+
+	// Guessing codes counts against the same limits as guessing passwords
+	ip := c.ClientIP()
+	if wait := auth.LoginLockedFor(user.Email, ip); wait > 0 {
+		tooManyLoginAttempts(c, wait)
+		return
+	}
+	if !checkSecondFactor(config.DB, user, body.Code, body.RecoveryCode) {
+		auth.RecordLoginFailure(user.Email, ip)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+	auth.RecordLoginSuccess(user.Email)
+
+	tokens, err := auth.StartSession(user, requestClient(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Login successful",
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+	})
+}
+
+// checkSecondFactor reports whether a TOTP code or recovery code is valid for
+// the user, using it up so it cannot be presented again
+func checkSecondFactor(tx *gorm.DB, user models.User, code, recoveryCode string) bool {
+	if recoveryCode != "" {
+		return auth.UseRecoveryCode(tx, user.ID, recoveryCode)
+	}
+
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, code, user.TOTPLastStep)
+	if !ok {
+		return false
+	}
+	// Two requests racing with the same code cannot both move the step forward
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	return result.Error == nil && result.RowsAffected > 0
+}
@@ -0,0 +1,70 @@
+// backend/api/two_factor_controller_test.go
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/auth"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestDisableTwoFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := auth.Throttle
+	auth.Throttle = auth.NewMemoryAttemptStore()
+	t.Cleanup(func() { auth.Throttle = previous })
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+	key, _ := totp.Generate(totp.GenerateOpts{Issuer: "Frigga", AccountName: "ada@example.com"})
+	code, _ := totp.GenerateCode(key.Secret(), time.Now())
+
+	tests := []struct {
+		name     string
+		password string
+		body     string
+		want     int
+	}{
+		{"password and code", "password1", `{"password":"password1","code":"` + code + `"}`, http.StatusOK},
+		{"wrong password", "password1", `{"password":"wrong","code":"` + code + `"}`, http.StatusUnauthorized},
+		{"without a password, code", "", `{"code":"` + code + `"}`, http.StatusOK},
+		{"without a password, wrong code", "", `{"code":"000000"}`, http.StatusUnauthorized},
+		{"without a password, recovery code only", "", `{"recoveryCode":"abcd-efgh"}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			user := models.User{Name: "Ada", Email: "ada@example.com", TOTPEnabled: true, TOTPSecret: key.Secret()}
+			if tt.password != "" {
+				user.Password = string(hash)
+			}
+			mustCreate(t, db, &user)
+			session := models.Session{UserID: user.ID, LastSeenAt: time.Now()}
+			session.CreatedAt = time.Now().Add(-time.Hour)
+			mustCreate(t, db, &session)
+
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user", user)
+			c.Set("session_id", session.ID)
+			DisableTwoFactor(c)
+
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body)
+			}
+			db.First(&user, user.ID)
+			if user.TOTPEnabled != (tt.want != http.StatusOK) {
+				t.Errorf("two-factor authentication enabled = %v after %d", user.TOTPEnabled, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,510 @@
+// backend/api/workspace_controller.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/auth"
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/mailer"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// workspaceDB returns the database limited to the request's workspace, as
+// chosen by WorkspaceMiddleware
+func workspaceDB(c *gin.Context) *gorm.DB {
+	return config.DB.WithContext(c.Request.Context())
+}
+
+// allWorkspacesDB returns the database for the few endpoints that work across
+// workspaces, such as public links and the admin console
+func allWorkspacesDB(c *gin.Context) *gorm.DB {
+	return config.DB.WithContext(tenancy.AllWorkspaces(c.Request.Context()))
+}
+
+// workspaceMemberIDs is a subquery of the users in db's workspace
+func workspaceMemberIDs(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.WorkspaceMember{}).Select("user_id")
+}
+
+// findWorkspaceMember looks up a user by email among the members of db's
+// workspace. Users of other workspaces are not found, so nothing can be
+// shared with them.
+func findWorkspaceMember(db *gorm.DB, email string) (models.User, error) {
+	var user models.User
+	err := db.Where("email = ? AND id IN (?)", email, workspaceMemberIDs(db)).First(&user).Error
+	return user, err
+}
+
+// isWorkspaceMember reports whether a user belongs to db's workspace
+func isWorkspaceMember(db *gorm.DB, userID uint) bool {
+	var count int64
+	db.Model(&models.WorkspaceMember{}).Where("user_id = ?", userID).Count(&count)
+	return count > 0
+}
+
+// workspaceSummary is a workspace as seen by one of its members
+type workspaceSummary struct {
+	models.Workspace
+	Role models.WorkspaceRole `json:"role"`
+}
+
+// GetWorkspaces lists the workspaces the authenticated user belongs to
+func GetWorkspaces(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var workspaces []workspaceSummary
+	err := allWorkspacesDB(c).Model(&models.Workspace{}).
+		Select("workspaces.*, workspace_members.role").
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id AND workspace_members.deleted_at IS NULL").
+		Where("workspace_members.user_id = ?", user.ID).
+		Order("workspace_members.created_at, workspaces.id").
+		Scan(&workspaces).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workspaces"})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// CreateWorkspace creates a workspace with the authenticated user as its admin
+func CreateWorkspace(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+
+	var workspace models.Workspace
+	err := allWorkspacesDB(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		workspace, err = tenancy.CreateWorkspace(tx, body.Name, user)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workspace"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspaceSummary{Workspace: workspace, Role: models.WorkspaceAdminRole})
+}
+
+// GetWorkspaceMembers lists the members of a workspace
+func GetWorkspaceMembers(c *gin.Context) {
+	db, _, ok := loadWorkspace(c, false)
+	if !ok {
+		return
+	}
+
+	var members []models.WorkspaceMember
+	if err := db.Preload("User").Order("created_at").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// workspaceInvitationTTL is how long an invitation to a workspace can be accepted
+const workspaceInvitationTTL = 7 * 24 * time.Hour
+
+// InviteWorkspaceMember emails an invitation to join a workspace. Nobody
+// becomes a member until they accept, and the reply is the same whether or
+// not the address belongs to an account.
+func InviteWorkspaceMember(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	currentUser := userCtx.(models.User)
+	if !requireVerifiedEmail(c, currentUser) {
+		return
+	}
+
+	var body struct {
+		Email string               `json:"email"`
+		Role  models.WorkspaceRole `json:"role"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	body.Email = strings.TrimSpace(body.Email)
+	if body.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is required"})
+		return
+	}
+	if body.Role == "" {
+		body.Role = models.WorkspaceMemberRole
+	}
+	if !body.Role.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be one of MEMBER or ADMIN"})
+		return
+	}
+
+	db, _, ok := loadWorkspace(c, true)
+	if !ok {
+		return
+	}
+
+	// Admins can list the members anyway, so this gives nothing away
+	if _, err := findWorkspaceMember(db, body.Email); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "This person is already a member of the workspace"})
+		return
+	}
+
+	token, err := auth.NewToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+	invitation := models.WorkspaceInvitation{
+		Email:       body.Email,
+		Role:        body.Role,
+		TokenHash:   auth.HashToken(token),
+		InvitedByID: currentUser.ID,
+		ExpiresAt:   time.Now().Add(workspaceInvitationTTL),
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// A new invitation replaces any pending one for the same address
+		if err := tx.Where("email = ? AND accepted_at IS NULL", body.Email).Delete(&models.WorkspaceInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&invitation).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	var workspace models.Workspace
+	db.First(&workspace, invitation.WorkspaceID)
+	mailer.SendAsync(mailer.Message{
+		To:      body.Email,
+		Subject: fmt.Sprintf("Join %s", workspace.Name),
+		Body: fmt.Sprintf("Hi,\n\n%s invited you to the %s workspace. Open the link below to accept; if you do not have an account yet, register with this email address first. The invitation expires in 7 days.\n\n%s/invitations/accept?token=%s\n",
+			currentUser.Name, workspace.Name, config.GetAppURL(), url.QueryEscape(token)),
+	})
+
+	invitation.InvitedBy = currentUser
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// GetWorkspaceInvitations lists the invitations to a workspace that can still be accepted
+func GetWorkspaceInvitations(c *gin.Context) {
+	db, _, ok := loadWorkspace(c, true)
+	if !ok {
+		return
+	}
+
+	var invitations []models.WorkspaceInvitation
+	result := db.Preload("InvitedBy").
+		Where("accepted_at IS NULL AND expires_at > ?", time.Now()).
+		Order("created_at").
+		Find(&invitations)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeWorkspaceInvitation withdraws an invitation that has not been accepted
+func RevokeWorkspaceInvitation(c *gin.Context) {
+	invitationID, ok := parseID(c.Param("invitationId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	db, _, ok := loadWorkspace(c, true)
+	if !ok {
+		return
+	}
+
+	var invitation models.WorkspaceInvitation
+	if err := db.Where("accepted_at IS NULL").First(&invitation, invitationID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+	if err := db.Delete(&invitation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptWorkspaceInvitation makes the authenticated user a member of the
+// workspace they were invited to. The invitation must have been sent to
+// their verified email address.
+func AcceptWorkspaceInvitation(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if !user.IsEmailVerified() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address before joining a workspace"})
+		return
+	}
+
+	var invitation models.WorkspaceInvitation
+	err := allWorkspacesDB(c).
+		Where("token_hash = ? AND accepted_at IS NULL AND expires_at > ?", auth.HashToken(body.Token), time.Now()).
+		First(&invitation).Error
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invitation"})
+		return
+	}
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This invitation was sent to a different email address"})
+		return
+	}
+
+	db := config.DB.WithContext(tenancy.WithWorkspace(c.Request.Context(), invitation.WorkspaceID))
+	var membership models.WorkspaceMember
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Only one of two concurrent accepts can win
+		result := tx.Model(&models.WorkspaceInvitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		err := tx.Where("user_id = ?", user.ID).First(&membership).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			membership = models.WorkspaceMember{UserID: user.ID, Role: invitation.Role}
+			return tx.Create(&membership).Error
+		case err != nil:
+			return err
+		case invitation.Role == models.WorkspaceAdminRole && membership.Role != models.WorkspaceAdminRole:
+			membership.Role = models.WorkspaceAdminRole
+			return tx.Model(&membership).Update("role", membership.Role).Error
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invitation"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	var workspace models.Workspace
+	db.First(&workspace, invitation.WorkspaceID)
+
+	c.JSON(http.StatusOK, workspaceSummary{Workspace: workspace, Role: membership.Role})
+}
+
+// UpdateWorkspaceMember changes the role of a workspace member
+func UpdateWorkspaceMember(c *gin.Context) {
+	var body struct {
+		Role models.WorkspaceRole `json:"role"`
+	}
+	if err := c.Bind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if !body.Role.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be one of MEMBER or ADMIN"})
+		return
+	}
+
+	db, _, ok := loadWorkspace(c, true)
+	if !ok {
+		return
+	}
+
+	userID, ok := parseID(c.Param("userId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	var membership models.WorkspaceMember
+	if err := db.Where("user_id = ?", userID).First(&membership).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if membership.Role == models.WorkspaceAdminRole && body.Role != models.WorkspaceAdminRole && isLastWorkspaceAdmin(db) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A workspace needs at least one admin"})
+		return
+	}
+
+	if err := db.Model(&membership).Update("role", body.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+	db.Preload("User").First(&membership, membership.ID)
+
+	c.JSON(http.StatusOK, membership)
+}
+
+// RemoveWorkspaceMember removes a user from a workspace along with their
+// access to its documents, spaces and groups. Members can always leave;
+// removing others requires being a workspace admin. The documents they
+// wrote stay in the workspace.
+func RemoveWorkspaceMember(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	db, current, ok := loadWorkspace(c, false)
+	if !ok {
+		return
+	}
+
+	userID, ok := parseID(c.Param("userId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	var membership models.WorkspaceMember
+	if err := db.Where("user_id = ?", userID).First(&membership).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if membership.UserID != user.ID && current.Role != models.WorkspaceAdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only workspace admins can manage members"})
+		return
+	}
+
+	if membership.Role == models.WorkspaceAdminRole && isLastWorkspaceAdmin(db) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A workspace needs at least one admin"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := removeWorkspaceAccess(tx, membership.UserID, user.ID); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&membership).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// loadWorkspace checks the authenticated user belongs to the workspace in
+// the "id" parameter, and administers it if adminOnly is set, replying with
+// an error otherwise. It returns the database limited to that workspace and
+// the user's membership.
+func loadWorkspace(c *gin.Context, adminOnly bool) (*gorm.DB, models.WorkspaceMember, bool) {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(models.User)
+
+	var membership models.WorkspaceMember
+	workspaceID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return nil, membership, false
+	}
+
+	// Workspaces the user is not in are not found, so their IDs reveal nothing
+	db := config.DB.WithContext(tenancy.WithWorkspace(c.Request.Context(), uint(workspaceID)))
+	if err := db.Where("user_id = ?", user.ID).First(&membership).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return nil, membership, false
+	}
+
+	if adminOnly && membership.Role != models.WorkspaceAdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only workspace admins can manage members"})
+		return nil, membership, false
+	}
+	return db, membership, true
+}
+
+func isLastWorkspaceAdmin(db *gorm.DB) bool {
+	var count int64
+	db.Model(&models.WorkspaceMember{}).Where("role = ?", models.WorkspaceAdminRole).Count(&count)
+	return count <= 1
+}
+
+// removeWorkspaceAccess revokes a user's grants on the documents, spaces and
+// groups of tx's workspace, recording each revoked document permission as
+// done by actorID. Groups the user was the only admin of get their
+// longest-standing member as admin.
+func removeWorkspaceAccess(tx *gorm.DB, userID, actorID uint) error {
+	documentIDs := func() *gorm.DB { return tx.Unscoped().Model(&models.Document{}).Select("id") }
+	spaceIDs := func() *gorm.DB { return tx.Unscoped().Model(&models.Space{}).Select("id") }
+	groupIDs := func() *gorm.DB { return tx.Unscoped().Model(&models.Group{}).Select("id") }
+
+	var permissions []models.Permission
+	if err := tx.Where("user_id = ? AND document_id IN (?)", userID, documentIDs()).Find(&permissions).Error; err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		err := tx.Create(&models.PermissionEvent{
+			DocumentID: permission.DocumentID,
+			UserID:     &userID,
+			Action:     models.PermissionRevoked,
+			Level:      permission.Level,
+			ActorID:    &actorID,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var adminOf []uint
+	tx.Model(&models.GroupMember{}).Where("user_id = ? AND is_admin = ? AND group_id IN (?)", userID, true, groupIDs()).Pluck("group_id", &adminOf)
+	for _, groupID := range adminOf {
+		var admins int64
+		tx.Model(&models.GroupMember{}).Where("group_id = ? AND is_admin = ? AND user_id <> ?", groupID, true, userID).Count(&admins)
+		if admins > 0 {
+			continue
+		}
+		var successor models.GroupMember
+		if err := tx.Where("group_id = ? AND user_id <> ?", groupID, userID).Order("created_at").First(&successor).Error; err != nil {
+			continue
+		}
+		if err := tx.Model(&models.GroupMember{}).Where("id = ?", successor.ID).Update("is_admin", true).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Unscoped().Where("user_id = ? AND document_id IN (?)", userID, documentIDs()).Delete(&models.Permission{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("user_id = ? AND space_id IN (?)", userID, spaceIDs()).Delete(&models.SpacePermission{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("user_id = ? AND group_id IN (?)", userID, groupIDs()).Delete(&models.GroupMember{}).Error
+}
@@ -0,0 +1,20 @@
+// backend/auth/admins.go
+package auth
+
+import (
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+)
+
+// PromoteConfiguredAdmins gives the admin role to the users listed in
+// ADMIN_EMAILS, so a fresh install has someone to run the admin console.
+// Unverified accounts are skipped, as anyone could have registered them.
+func PromoteConfiguredAdmins() error {
+	emails := config.GetAdminEmails()
+	if len(emails) == 0 {
+		return nil
+	}
+	return config.DB.Model(&models.User{}).
+		Where("email IN ? AND email_verified_at IS NOT NULL AND role <> ?", emails, models.AdminRole).
+		Update("role", models.AdminRole).Error
+}
@@ -0,0 +1,228 @@
+// backend/auth/keys.go
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is one key of the key set, identified in tokens by its kid.
+// Retired keys only have a public half and are used to verify tokens
+// signed before a rotation.
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// keySet holds the asymmetric keys tokens are signed and verified with. When
+// it is nil, tokens are signed with the HS256 secret instead.
+type keySet struct {
+	keys    map[string]*signingKey
+	current *signingKey
+}
+
+var keys *keySet
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// LoadKeys reads the signing keys from JWT_KEYS_DIR. Each .pem file holds an
+// RSA or Ed25519 key and its file name, without the extension, is its kid.
+// The key named by JWT_SIGNING_KEY_ID signs new tokens, defaulting to the
+// last private key by name; the others still verify tokens they signed, so
+// keys can be rotated without logging anyone out. Without JWT_KEYS_DIR
+// tokens are signed with JWT_SECRET using HS256.
+func LoadKeys() error {
+	// Tokens only this server reads are addressed to the issuer, so an access
+	// token's audience must be something else
+	if config.GetJWTAudience() == config.GetJWTIssuer() {
+		return errors.New("JWT_AUDIENCE must differ from JWT_ISSUER")
+	}
+
+	dir := config.GetJWTKeysDir()
+	if dir == "" {
+		keys = nil
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	set := &keySet{keys: make(map[string]*signingKey)}
+	for _, path := range paths {
+		key, err := readKey(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		set.keys[key.id] = key
+		if key.private != nil {
+			set.current = key
+		}
+	}
+
+	if id := config.GetJWTSigningKeyID(); id != "" {
+		set.current = set.keys[id]
+		if set.current == nil || set.current.private == nil {
+			return fmt.Errorf("no private key with kid %q in %s", id, dir)
+		}
+	}
+	if set.current == nil {
+		return fmt.Errorf("no private keys in %s", dir)
+	}
+
+	keys = set
+	return nil
+}
+
+// PublicKeys returns the public keys that tokens may be signed with, for
+// other services to verify them. It is empty when HS256 is used.
+func PublicKeys() []JWK {
+	jwks := []JWK{}
+	if keys == nil {
+		return jwks
+	}
+
+	ids := make([]string, 0, len(keys.keys))
+	for id := range keys.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		key := keys.keys[id]
+		jwk := JWK{KeyID: key.id, Algorithm: key.method.Alg(), Use: "sig"}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks
+}
+
+// signClaims signs a JWT with the current key, or the HS256 secret when no
+// key set is configured. It adds the issuer and the audience for the token's
+// "typ".
+func signClaims(claims jwt.MapClaims) (string, error) {
+	claims["iss"] = config.GetJWTIssuer()
+	claims["aud"] = tokenAudience(claims["typ"])
+
+	if keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(config.GetJWTSecret()))
+	}
+
+	token := jwt.NewWithClaims(keys.current.method, claims)
+	token.Header["kid"] = keys.current.id
+	return token.SignedString(keys.current.private)
+}
+
+// tokenAudience returns who a token of type tokenType is for. Access tokens
+// are for the API and anything else that accepts them; 2FA challenges and
+// OIDC flows are only ever read back by this server, so they are addressed
+// to the issuer and fail any verifier that checks for the API audience.
+func tokenAudience(tokenType interface{}) string {
+	if tokenType == "access" {
+		return config.GetJWTAudience()
+	}
+	return config.GetJWTIssuer()
+}
+
+// verificationKey finds the key a token was signed with, refusing tokens
+// whose algorithm does not match the key
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	if keys == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(config.GetJWTSecret()), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := keys.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.public, nil
+}
+
+// readKey parses a PEM file holding a private key, or a public key for a
+// retired key
+func readKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	key := &signingKey{id: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, expected RSA or Ed25519", parsed)
+	}
+	return key, nil
+}
@@ -0,0 +1,104 @@
+// backend/auth/migrate.go
+package auth
+
+import (
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"gorm.io/gorm"
+)
+
+// VerifyExistingEmails marks every account as verified. It runs once, when
+// email verification is added to an existing database, so accounts created
+// before then keep the access they had.
+func VerifyExistingEmails() error {
+	return config.DB.Model(&models.User{}).
+		Where("email_verified_at IS NULL").
+		Update("email_verified_at", gorm.Expr("created_at")).Error
+}
+
+// legacyRefreshToken is a refresh token from before sessions existed, when the
+// tokens of one login were grouped by a family ID
+type legacyRefreshToken struct {
+	ID        uint
+	UserID    uint
+	FamilyID  string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// MigrateRefreshTokenFamilies turns each family of refresh tokens from before
+// sessions existed into a session, so those logins keep working, and drops
+// the columns sessions replaced. It has to run before the models are
+// migrated, which cannot add the session ID to existing rows.
+func MigrateRefreshTokenFamilies() error {
+	migrator := config.DB.Migrator()
+	if !migrator.HasTable(&models.RefreshToken{}) || !migrator.HasColumn(&models.RefreshToken{}, "family_id") {
+		return nil
+	}
+	if err := migrator.AutoMigrate(&models.Session{}); err != nil {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if !tx.Migrator().HasColumn(&models.RefreshToken{}, "session_id") {
+			if err := tx.Exec("ALTER TABLE refresh_tokens ADD COLUMN session_id bigint").Error; err != nil {
+				return err
+			}
+		}
+
+		var tokens []legacyRefreshToken
+		err := tx.Table("refresh_tokens").
+			Where("session_id IS NULL AND deleted_at IS NULL").
+			Order("created_at").
+			Find(&tokens).Error
+		if err != nil {
+			return err
+		}
+
+		sessions := make(map[string]*models.Session)
+		var families []string
+		for _, token := range tokens {
+			session, ok := sessions[token.FamilyID]
+			if !ok {
+				session = &models.Session{UserID: token.UserID}
+				sessions[token.FamilyID] = session
+				families = append(families, token.FamilyID)
+			}
+			session.LastSeenAt = token.CreatedAt
+			if token.RevokedAt != nil {
+				session.RevokedAt = token.RevokedAt
+			}
+		}
+		for _, family := range families {
+			session := sessions[family]
+			if err := tx.Create(session).Error; err != nil {
+				return err
+			}
+			err := tx.Table("refresh_tokens").Where("family_id = ?", family).Update("session_id", session.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// Tokens that never belonged to a family cannot be used any more
+		if err := tx.Exec("DELETE FROM refresh_tokens WHERE session_id IS NULL").Error; err != nil {
+			return err
+		}
+
+		for _, index := range []string{"idx_refresh_tokens_user_id", "idx_refresh_tokens_family_id"} {
+			if tx.Migrator().HasIndex(&models.RefreshToken{}, index) {
+				if err := tx.Migrator().DropIndex(&models.RefreshToken{}, index); err != nil {
+					return err
+				}
+			}
+		}
+		for _, column := range []string{"user_id", "family_id", "revoked_at"} {
+			if err := tx.Migrator().DropColumn(&models.RefreshToken{}, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
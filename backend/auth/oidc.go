@@ -0,0 +1,267 @@
+// backend/auth/oidc.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+// oidcFlowTTL is how long a user has to sign in at the identity provider
+const oidcFlowTTL = 10 * time.Minute
+
+var (
+	// ErrInvalidOIDCFlow is returned when the callback from the identity
+	// provider does not match a sign-in started here
+	ErrInvalidOIDCFlow = errors.New("invalid or expired sign-in")
+
+	// ErrOIDCEmailTaken is returned when the identity provider signs in
+	// someone with the email of an existing account but has not verified
+	// that they own it, so the accounts cannot safely be linked
+	ErrOIDCEmailTaken = errors.New("an account with this email already exists")
+)
+
+// OIDCFlow is what is remembered between sending a user to the identity
+// provider and their return: the state that ties the two together, the nonce
+// expected in the ID token and the PKCE code verifier
+type OIDCFlow struct {
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+// OIDCIdentity is who the identity provider says signed in
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+var (
+	oidcMu       sync.Mutex
+	oidcProvider *oidc.Provider
+)
+
+// StartOIDCFlow begins a sign-in with the identity provider and returns the
+// URL to send the user to
+func StartOIDCFlow(ctx context.Context) (string, OIDCFlow, error) {
+	_, oauthConfig, err := oidcClient(ctx)
+	if err != nil {
+		return "", OIDCFlow{}, err
+	}
+
+	state, err := NewToken()
+	if err != nil {
+		return "", OIDCFlow{}, err
+	}
+	nonce, err := NewToken()
+	if err != nil {
+		return "", OIDCFlow{}, err
+	}
+	flow := OIDCFlow{State: state, Nonce: nonce, Verifier: oauth2.GenerateVerifier()}
+
+	authURL := oauthConfig.AuthCodeURL(flow.State, oidc.Nonce(flow.Nonce), oauth2.S256ChallengeOption(flow.Verifier))
+	return authURL, flow, nil
+}
+
+// FinishOIDCFlow exchanges the authorization code from the callback for an
+// ID token and returns the identity in it
+func FinishOIDCFlow(ctx context.Context, flow OIDCFlow, code string) (OIDCIdentity, error) {
+	provider, oauthConfig, err := oidcClient(ctx)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return OIDCIdentity{}, errors.New("no id_token in token response")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: oauthConfig.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+	if idToken.Nonce != flow.Nonce {
+		return OIDCIdentity{}, ErrInvalidOIDCFlow
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	return OIDCIdentity{
+		Issuer:        idToken.Issuer,
+		Subject:       idToken.Subject,
+		Email:         strings.TrimSpace(claims.Email),
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// SignOIDCFlow seals a flow so it can be kept in a cookie
+func SignOIDCFlow(flow OIDCFlow) (string, error) {
+	return signClaims(jwt.MapClaims{
+		"typ":      "oidc",
+		"state":    flow.State,
+		"nonce":    flow.Nonce,
+		"verifier": flow.Verifier,
+		"exp":      time.Now().Add(oidcFlowTTL).Unix(),
+	})
+}
+
+// ParseOIDCFlow opens a flow sealed by SignOIDCFlow
+func ParseOIDCFlow(value string) (OIDCFlow, error) {
+	claims, err := parseClaims(value, "oidc")
+	if err != nil {
+		return OIDCFlow{}, ErrInvalidOIDCFlow
+	}
+	state, _ := claims["state"].(string)
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+	if state == "" || nonce == "" || verifier == "" {
+		return OIDCFlow{}, ErrInvalidOIDCFlow
+	}
+	return OIDCFlow{State: state, Nonce: nonce, Verifier: verifier}, nil
+}
+
+// ResolveOIDCUser finds the user an identity belongs to. An identity seen
+// before maps to the same user; otherwise it is linked to the account with
+// the same email if the provider verified that email, or a new account is
+// created for it.
+func ResolveOIDCUser(identity OIDCIdentity) (models.User, error) {
+	var user models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.UserIdentity
+		err := tx.Where("issuer = ? AND subject = ?", identity.Issuer, identity.Subject).First(&existing).Error
+		if err == nil {
+			return tx.First(&user, existing.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if identity.Email == "" {
+			return errors.New("identity provider did not share an email address")
+		}
+
+		err = tx.Where("email = ?", identity.Email).First(&user).Error
+		switch {
+		case err == nil:
+			if !identity.EmailVerified {
+				return ErrOIDCEmailTaken
+			}
+			if !user.IsEmailVerified() {
+				if err := reclaimUnverifiedAccount(tx, &user); err != nil {
+					return err
+				}
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// Users who sign in through the provider have no local password
+			user = models.User{Name: identity.Name, Email: identity.Email}
+			if user.Name == "" {
+				user.Name = identity.Email
+			}
+			if identity.EmailVerified {
+				now := time.Now()
+				user.EmailVerifiedAt = &now
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			if _, err := tenancy.CreatePersonalWorkspace(tx, user); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:  user.ID,
+			Issuer:  identity.Issuer,
+			Subject: identity.Subject,
+			Email:   identity.Email,
+		}).Error
+	})
+	return user, err
+}
+
+// reclaimUnverifiedAccount hands an account whose email was never verified
+// to the owner of that email, as proven by the identity provider. Anyone could
+// have registered it, so whatever they set up to get back in is removed: the
+// password, second factor, sessions and access tokens.
+func reclaimUnverifiedAccount(tx *gorm.DB, user *models.User) error {
+	now := time.Now()
+	err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"email_verified_at": now,
+		"password":          "",
+		"pending_email":     "",
+		"totp_enabled":      false,
+		"totp_secret":       "",
+	}).Error
+	if err != nil {
+		return err
+	}
+	user.EmailVerifiedAt = &now
+	user.Password = ""
+	user.PendingEmail = ""
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+
+	err = tx.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", user.ID).
+		Update("revoked_at", now).Error
+	if err != nil {
+		return err
+	}
+	for _, model := range []interface{}{&models.PersonalAccessToken{}, &models.RecoveryCode{}, &models.UserToken{}} {
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// oidcClient returns the identity provider, discovering it on first use, and
+// the OAuth2 settings for signing in with it
+func oidcClient(ctx context.Context) (*oidc.Provider, *oauth2.Config, error) {
+	oidcMu.Lock()
+	defer oidcMu.Unlock()
+
+	if oidcProvider == nil {
+		provider, err := oidc.NewProvider(ctx, config.GetOIDCIssuerURL())
+		if err != nil {
+			return nil, nil, err
+		}
+		oidcProvider = provider
+	}
+
+	clientID, clientSecret := config.GetOIDCClient()
+	return oidcProvider, &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  config.GetOIDCRedirectURL(),
+		Endpoint:     oidcProvider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+	}, nil
+}
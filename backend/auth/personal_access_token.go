@@ -0,0 +1,71 @@
+// backend/auth/personal_access_token.go
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+)
+
+// personalAccessTokenPrefix starts every personal access token, so they can
+// be told apart from JWTs and spotted by secret scanners
+const personalAccessTokenPrefix = "frg_"
+
+// ErrInvalidPersonalAccessToken is returned for personal access tokens that
+// are unknown, revoked or expired
+var ErrInvalidPersonalAccessToken = errors.New("invalid or expired personal access token")
+
+// IsPersonalAccessToken reports whether a bearer token looks like a personal
+// access token rather than a JWT
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, personalAccessTokenPrefix)
+}
+
+// CreatePersonalAccessToken makes a new personal access token for a user and
+// returns it along with its record. The token cannot be retrieved again.
+func CreatePersonalAccessToken(userID uint, name string, scope models.TokenScope, expiresAt *time.Time) (string, models.PersonalAccessToken, error) {
+	secret, err := NewToken()
+	if err != nil {
+		return "", models.PersonalAccessToken{}, err
+	}
+	token := personalAccessTokenPrefix + secret
+
+	pat := models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    token[:len(personalAccessTokenPrefix)+6],
+		TokenHash: HashToken(token),
+		Scope:     scope,
+		ExpiresAt: expiresAt,
+	}
+	if err := config.DB.Create(&pat).Error; err != nil {
+		return "", models.PersonalAccessToken{}, err
+	}
+	return token, pat, nil
+}
+
+// RevokePersonalAccessTokens stops every personal access token of a user
+// from working, for when their password may have been compromised
+func RevokePersonalAccessTokens(userID uint) error {
+	return config.DB.Where("user_id = ?", userID).Delete(&models.PersonalAccessToken{}).Error
+}
+
+// AuthenticatePersonalAccessToken looks up a personal access token and
+// records that it was just used
+func AuthenticatePersonalAccessToken(token string) (models.PersonalAccessToken, error) {
+	var pat models.PersonalAccessToken
+	if err := config.DB.Where("token_hash = ?", HashToken(token)).First(&pat).Error; err != nil {
+		return pat, ErrInvalidPersonalAccessToken
+	}
+	if pat.ExpiresAt != nil && !pat.ExpiresAt.After(time.Now()) {
+		return pat, ErrInvalidPersonalAccessToken
+	}
+
+	if pat.LastUsedAt == nil || time.Since(*pat.LastUsedAt) > lastSeenInterval {
+		config.DB.Model(&models.PersonalAccessToken{}).Where("id = ?", pat.ID).Update("last_used_at", time.Now())
+	}
+	return pat, nil
+}
@@ -0,0 +1,222 @@
+// backend/auth/session.go
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for refresh tokens that are unknown,
+	// expired or belong to a revoked session
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+	// ErrRefreshTokenReused is returned when a refresh token that was already
+	// exchanged is presented again. It means the token was probably stolen,
+	// so its session is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+const (
+	// lastSeenInterval limits how often a session's last-seen time is written
+	lastSeenInterval = time.Minute
+
+	// recentSignInWindow is how long after signing in a session counts as
+	// proof of who the user is, for accounts without a password
+	recentSignInWindow = 5 * time.Minute
+)
+
+// Tokens is what a client receives when it logs in or refreshes
+type Tokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // seconds until the access token expires
+}
+
+// Client describes the device a request came from
+type Client struct {
+	UserAgent string
+	IPAddress string
+}
+
+// StartSession logs a user in on a device
+func StartSession(user models.User, client Client) (Tokens, error) {
+	var tokens Tokens
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		session := models.Session{
+			UserID:     user.ID,
+			UserAgent:  truncate(client.UserAgent, 512),
+			IPAddress:  client.IPAddress,
+			LastSeenAt: time.Now(),
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = issueTokens(tx, session)
+		return err
+	})
+	return tokens, err
+}
+
+// Refresh exchanges a refresh token for new tokens in the same session. Each
+// refresh token can only be used once.
+func Refresh(refreshToken string, client Client) (Tokens, error) {
+	var stored models.RefreshToken
+	if err := config.DB.Where("token_hash = ?", HashToken(refreshToken)).First(&stored).Error; err != nil {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	var session models.Session
+	if err := config.DB.Where("revoked_at IS NULL").First(&session, stored.SessionID).Error; err != nil {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	if !stored.ExpiresAt.After(time.Now()) {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		if err := RevokeSession(session.ID); err != nil {
+			return Tokens{}, err
+		}
+		return Tokens{}, ErrRefreshTokenReused
+	}
+
+	var tokens Tokens
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Only one of two concurrent refreshes with the same token can win
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", stored.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		err := tx.Model(&models.Session{}).Where("id = ?", session.ID).Updates(map[string]interface{}{
+			"ip_address":   client.IPAddress,
+			"last_seen_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		tokens, err = issueTokens(tx, session)
+		return err
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if err := RevokeSession(session.ID); err != nil {
+			return Tokens{}, err
+		}
+	}
+	return tokens, err
+}
+
+// RevokeRefreshToken ends the session a refresh token belongs to
+func RevokeRefreshToken(refreshToken string) error {
+	var stored models.RefreshToken
+	if err := config.DB.Where("token_hash = ?", HashToken(refreshToken)).First(&stored).Error; err != nil {
+		return ErrInvalidRefreshToken
+	}
+	return RevokeSession(stored.SessionID)
+}
+
+// RevokeSession ends a session: its refresh tokens stop working and so do
+// the access tokens issued with them
+func RevokeSession(sessionID uint) error {
+	return config.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUser ends every session of a user
+func RevokeUser(userID uint) error {
+	return config.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// ActiveSessions lists the sessions of a user that have not been revoked,
+// most recently used first
+func ActiveSessions(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := config.DB.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("last_seen_at desc").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// TouchSession reports whether a session is still active and records that
+// it was just used
+func TouchSession(sessionID uint) bool {
+	var session models.Session
+	if err := config.DB.Where("revoked_at IS NULL").First(&session, sessionID).Error; err != nil {
+		return false
+	}
+
+	if time.Since(session.LastSeenAt) > lastSeenInterval {
+		config.DB.Model(&models.Session{}).Where("id = ?", session.ID).Update("last_seen_at", time.Now())
+	}
+	return true
+}
+
+// RecentlySignedIn reports whether a session is active and was started within
+// the last few minutes. Personal access tokens have no session and never are.
+func RecentlySignedIn(sessionID uint) bool {
+	if sessionID == 0 {
+		return false
+	}
+	var count int64
+	config.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL AND created_at > ?", sessionID, time.Now().Add(-recentSignInWindow)).
+		Count(&count)
+	return count > 0
+}
+
+// issueTokens signs an access token and stores a new refresh token for a session
+func issueTokens(db *gorm.DB, session models.Session) (Tokens, error) {
+	refreshToken, err := NewToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	err = db.Create(&models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(config.GetRefreshTokenTTL()),
+	}).Error
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	accessToken, err := issueAccessToken(session.UserID, session.ID)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(config.GetAccessTokenTTL().Seconds()),
+	}, nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
+
+// RevokeOtherSessions ends every session of a user except one, usually the
+// session the request came from
+func RevokeOtherSessions(userID, keepID uint) error {
+	return config.DB.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,147 @@
+// backend/auth/session_test.go
+package auth
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB installs an empty SQLite database as config.DB and signs
+// tokens with a test secret
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	err = db.AutoMigrate(&models.User{}, &models.Session{}, &models.RefreshToken{}, &models.LoginAttempt{})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() { config.DB = previous })
+	return db
+}
+
+func startTestSession(t *testing.T) (models.User, Tokens) {
+	t.Helper()
+	user := models.User{Name: "Ada", Email: "ada@example.com", Password: "x"}
+	if err := config.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	tokens, err := StartSession(user, Client{UserAgent: "test", IPAddress: "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	return user, tokens
+}
+
+func TestRefresh(t *testing.T) {
+	openTestDB(t)
+	user, tokens := startTestSession(t)
+
+	claims, err := ParseAccessToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("ParseAccessToken: %v", err)
+	}
+	if claims.UserID != user.ID {
+		t.Errorf("access token is for user %d, want %d", claims.UserID, user.ID)
+	}
+
+	refreshed, err := Refresh(tokens.RefreshToken, Client{IPAddress: "192.0.2.2"})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if refreshed.RefreshToken == tokens.RefreshToken {
+		t.Error("Refresh returned the same refresh token")
+	}
+	refreshedClaims, err := ParseAccessToken(refreshed.AccessToken)
+	if err != nil {
+		t.Fatalf("ParseAccessToken after refresh: %v", err)
+	}
+	if refreshedClaims.SessionID != claims.SessionID {
+		t.Errorf("refresh moved to session %d, want %d", refreshedClaims.SessionID, claims.SessionID)
+	}
+
+	var session models.Session
+	config.DB.First(&session, claims.SessionID)
+	if session.IPAddress != "192.0.2.2" {
+		t.Errorf("session IP = %q, want the refreshing client's", session.IPAddress)
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	openTestDB(t)
+	_, tokens := startTestSession(t)
+
+	refreshed, err := Refresh(tokens.RefreshToken, Client{})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	if _, err := Refresh(tokens.RefreshToken, Client{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reusing a refresh token returned %v, want ErrRefreshTokenReused", err)
+	}
+
+	// The legitimate holder's newer token dies with the session
+	if _, err := Refresh(refreshed.RefreshToken, Client{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refresh after reuse returned %v, want ErrInvalidRefreshToken", err)
+	}
+	claims, _ := ParseAccessToken(refreshed.AccessToken)
+	if TouchSession(claims.SessionID) {
+		t.Error("session is still active after its refresh token was reused")
+	}
+}
+
+func TestRefreshRejectsInvalidTokens(t *testing.T) {
+	openTestDB(t)
+	_, tokens := startTestSession(t)
+
+	if _, err := Refresh("unknown", Client{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("unknown token returned %v, want ErrInvalidRefreshToken", err)
+	}
+
+	config.DB.Model(&models.RefreshToken{}).Where("token_hash = ?", HashToken(tokens.RefreshToken)).
+		Update("expires_at", time.Now().Add(-time.Minute))
+	if _, err := Refresh(tokens.RefreshToken, Client{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("expired token returned %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	openTestDB(t)
+	_, tokens := startTestSession(t)
+
+	if err := RevokeRefreshToken(tokens.RefreshToken); err != nil {
+		t.Fatalf("RevokeRefreshToken: %v", err)
+	}
+	if _, err := Refresh(tokens.RefreshToken, Client{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refresh after logout returned %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestParseAccessTokenChecksType(t *testing.T) {
+	openTestDB(t)
+
+	challenge, err := IssueChallengeToken(1)
+	if err != nil {
+		t.Fatalf("IssueChallengeToken: %v", err)
+	}
+	if _, err := ParseAccessToken(challenge); err == nil {
+		t.Error("a 2FA challenge token was accepted as an access token")
+	}
+}
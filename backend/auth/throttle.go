@@ -0,0 +1,132 @@
+// backend/auth/throttle.go
+package auth
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+)
+
+const (
+	// Failures allowed before an account or IP address gets locked out
+	accountFreeFailures = 5
+	ipFreeFailures      = 20
+
+	// The first lockout lasts baseLockout and each further failure doubles
+	// it, up to maxLockout
+	baseLockout = 30 * time.Second
+	maxLockout  = time.Hour
+
+	// Failures are forgotten after this long without another one
+	failureWindow = 24 * time.Hour
+)
+
+// Attempts is what the throttle knows about one account or IP address
+type Attempts struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// AttemptStore keeps failed login counts
+type AttemptStore interface {
+	// Get returns the attempts recorded for a key
+	Get(key string) (Attempts, error)
+	// AddFailure counts a failed attempt, forgetting failures older than
+	// failureWindow first, and locks the key for as long as lockout says
+	AddFailure(key string, lockout func(failures int) time.Duration) (Attempts, error)
+	// Reset forgets a key
+	Reset(key string) error
+}
+
+// Throttle is the store used to throttle logins. It is set up from the
+// environment by ConfigureThrottle.
+var Throttle AttemptStore = NewMemoryAttemptStore()
+
+// ConfigureThrottle sets Throttle to the store chosen by LOGIN_THROTTLE_STORE
+func ConfigureThrottle() {
+	switch store := config.GetLoginThrottleStore(); store {
+	case "memory":
+		Throttle = NewMemoryAttemptStore()
+	case "database":
+		Throttle = DatabaseAttemptStore{}
+	default:
+		log.Fatalf("Unknown LOGIN_THROTTLE_STORE %q, expected memory or database", store)
+	}
+}
+
+// LoginLockedFor returns how long logins for an email from an IP address
+// are locked out, or zero if they are allowed
+func LoginLockedFor(email, ip string) time.Duration {
+	var wait time.Duration
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		attempts, err := Throttle.Get(key)
+		if err != nil {
+			log.Printf("Failed to read login attempts for %s: %v", key, err)
+			continue
+		}
+		if remaining := time.Until(attempts.LockedUntil); remaining > wait {
+			wait = remaining
+		}
+	}
+	return wait
+}
+
+// RecordLoginFailure counts a failed login against both the account and the
+// IP address it came from
+func RecordLoginFailure(email, ip string) {
+	if _, err := Throttle.AddFailure(accountKey(email), lockoutAfter(accountFreeFailures)); err != nil {
+		log.Printf("Failed to record login failure for %s: %v", email, err)
+	}
+	if _, err := Throttle.AddFailure(ipKey(ip), lockoutAfter(ipFreeFailures)); err != nil {
+		log.Printf("Failed to record login failure from %s: %v", ip, err)
+	}
+}
+
+// RecordLoginSuccess clears an account's failures. The IP address keeps its
+// count, so an attacker cannot reset it by logging into their own account.
+func RecordLoginSuccess(email string) {
+	if err := Throttle.Reset(accountKey(email)); err != nil {
+		log.Printf("Failed to reset login failures for %s: %v", email, err)
+	}
+}
+
+// UnlockAccount clears an account's failures and lockout
+func UnlockAccount(email string) error {
+	return Throttle.Reset(accountKey(email))
+}
+
+// UnlockIP clears an IP address's failures and lockout
+func UnlockIP(ip string) error {
+	return Throttle.Reset(ipKey(ip))
+}
+
+// lockoutAfter returns how long to lock out for after a number of failures,
+// doubling with every failure past the free ones
+func lockoutAfter(free int) func(failures int) time.Duration {
+	return func(failures int) time.Duration {
+		if failures < free {
+			return 0
+		}
+		lockout := baseLockout
+		for i := free; i < failures && lockout < maxLockout; i++ {
+			lockout *= 2
+		}
+		if lockout > maxLockout {
+			lockout = maxLockout
+		}
+		return lockout
+	}
+}
+
+// Keys are built from the email as typed rather than the user, so accounts
+// that do not exist are locked out the same way as ones that do
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
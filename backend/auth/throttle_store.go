@@ -0,0 +1,141 @@
+// backend/auth/throttle_store.go
+package auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"gorm.io/gorm"
+)
+
+// memoryStoreLimit is how many keys MemoryAttemptStore holds before it
+// drops the ones it no longer needs
+const memoryStoreLimit = 10000
+
+// MemoryAttemptStore keeps failed login counts in memory. Counts are lost on
+// restart and not shared between instances.
+type MemoryAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]Attempts
+}
+
+// NewMemoryAttemptStore returns an empty in-memory store
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{attempts: make(map[string]Attempts)}
+}
+
+// Get returns the attempts recorded for a key
+func (s *MemoryAttemptStore) Get(key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[key], nil
+}
+
+// AddFailure counts a failed attempt for a key
+func (s *MemoryAttemptStore) AddFailure(key string, lockout func(failures int) time.Duration) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if len(s.attempts) >= memoryStoreLimit {
+		s.prune(now)
+	}
+
+	attempts := s.attempts[key]
+	if now.Sub(attempts.LastFailureAt) > failureWindow {
+		attempts = Attempts{}
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = now
+	if d := lockout(attempts.Failures); d > 0 {
+		attempts.LockedUntil = now.Add(d)
+	}
+	s.attempts[key] = attempts
+	return attempts, nil
+}
+
+// Reset forgets a key
+func (s *MemoryAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+// prune drops keys whose failures have been forgotten and are not locked
+func (s *MemoryAttemptStore) prune(now time.Time) {
+	for key, attempts := range s.attempts {
+		if now.Sub(attempts.LastFailureAt) > failureWindow && now.After(attempts.LockedUntil) {
+			delete(s.attempts, key)
+		}
+	}
+}
+
+// DatabaseAttemptStore keeps failed login counts in the database, so they
+// survive restarts and are shared by every instance of the server
+type DatabaseAttemptStore struct{}
+
+// Get returns the attempts recorded for a key
+func (DatabaseAttemptStore) Get(key string) (Attempts, error) {
+	var row models.LoginAttempt
+	err := config.DB.Where("key = ?", key).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Attempts{}, nil
+	}
+	if err != nil {
+		return Attempts{}, err
+	}
+	return toAttempts(row), nil
+}
+
+// AddFailure counts a failed attempt for a key
+func (DatabaseAttemptStore) AddFailure(key string, lockout func(failures int) time.Duration) (Attempts, error) {
+	var row models.LoginAttempt
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Where(models.LoginAttempt{Key: key}).Attrs(models.LoginAttempt{LastFailureAt: now}).FirstOrCreate(&row).Error; err != nil {
+			return err
+		}
+
+		// The count is bumped in SQL so concurrent failures are not lost
+		err := tx.Model(&models.LoginAttempt{}).Where("id = ? AND last_failure_at < ?", row.ID, now.Add(-failureWindow)).
+			Update("failures", 0).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.LoginAttempt{}).Where("id = ?", row.ID).Updates(map[string]interface{}{
+			"failures":        gorm.Expr("failures + 1"),
+			"last_failure_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&row, row.ID).Error; err != nil {
+			return err
+		}
+
+		if d := lockout(row.Failures); d > 0 {
+			lockedUntil := now.Add(d)
+			row.LockedUntil = &lockedUntil
+			return tx.Model(&models.LoginAttempt{}).Where("id = ?", row.ID).Update("locked_until", lockedUntil).Error
+		}
+		return nil
+	})
+	return toAttempts(row), err
+}
+
+// Reset forgets a key
+func (DatabaseAttemptStore) Reset(key string) error {
+	return config.DB.Unscoped().Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+func toAttempts(row models.LoginAttempt) Attempts {
+	attempts := Attempts{Failures: row.Failures, LastFailureAt: row.LastFailureAt}
+	if row.LockedUntil != nil {
+		attempts.LockedUntil = *row.LockedUntil
+	}
+	return attempts
+}
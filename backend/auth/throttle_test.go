@@ -0,0 +1,129 @@
+// backend/auth/throttle_test.go
+package auth
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLockoutAfter(t *testing.T) {
+	lockout := lockoutAfter(5)
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 0},
+		{4, 0},
+		{5, baseLockout},
+		{6, 2 * baseLockout},
+		{7, 4 * baseLockout},
+		{11, 64 * baseLockout},
+		{12, maxLockout},
+		{1000, maxLockout},
+	}
+
+	for _, tt := range tests {
+		if got := lockout(tt.failures); got != tt.want {
+			t.Errorf("lockout after %d failures = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+// testAttemptStore runs the behaviour every AttemptStore must have
+func testAttemptStore(t *testing.T, store AttemptStore) {
+	lockout := lockoutAfter(3)
+
+	for i := 1; i <= 2; i++ {
+		attempts, err := store.AddFailure("account:a@example.com", lockout)
+		if err != nil {
+			t.Fatalf("AddFailure: %v", err)
+		}
+		if attempts.Failures != i || !attempts.LockedUntil.IsZero() {
+			t.Fatalf("after %d failures got %+v, want no lockout", i, attempts)
+		}
+	}
+
+	attempts, err := store.AddFailure("account:a@example.com", lockout)
+	if err != nil {
+		t.Fatalf("AddFailure: %v", err)
+	}
+	if remaining := time.Until(attempts.LockedUntil); remaining <= 0 || remaining > baseLockout {
+		t.Errorf("after 3 failures locked for %v, want up to %v", remaining, baseLockout)
+	}
+
+	stored, err := store.Get("account:a@example.com")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if stored.Failures != 3 || !stored.LockedUntil.Equal(attempts.LockedUntil) {
+		t.Errorf("Get = %+v, want %+v", stored, attempts)
+	}
+
+	other, _ := store.Get("account:b@example.com")
+	if other.Failures != 0 {
+		t.Errorf("an untouched key has %d failures", other.Failures)
+	}
+
+	if err := store.Reset("account:a@example.com"); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if stored, _ := store.Get("account:a@example.com"); stored.Failures != 0 || !stored.LockedUntil.IsZero() {
+		t.Errorf("after Reset got %+v", stored)
+	}
+}
+
+func TestMemoryAttemptStore(t *testing.T) {
+	testAttemptStore(t, NewMemoryAttemptStore())
+}
+
+func TestDatabaseAttemptStore(t *testing.T) {
+	openTestDB(t)
+	testAttemptStore(t, DatabaseAttemptStore{})
+}
+
+func TestMemoryAttemptStoreForgetsOldFailures(t *testing.T) {
+	store := NewMemoryAttemptStore()
+	store.attempts["ip:192.0.2.1"] = Attempts{Failures: 10, LastFailureAt: time.Now().Add(-failureWindow - time.Minute)}
+
+	attempts, _ := store.AddFailure("ip:192.0.2.1", lockoutAfter(ipFreeFailures))
+	if attempts.Failures != 1 {
+		t.Errorf("failures = %d, want the old ones forgotten", attempts.Failures)
+	}
+}
+
+func TestLoginLockedFor(t *testing.T) {
+	previous := Throttle
+	Throttle = NewMemoryAttemptStore()
+	t.Cleanup(func() { Throttle = previous })
+
+	for i := 0; i < accountFreeFailures; i++ {
+		if wait := LoginLockedFor("ada@example.com", "192.0.2.1"); wait != 0 {
+			t.Fatalf("locked out for %v after %d failures", wait, i)
+		}
+		RecordLoginFailure(" Ada@Example.com", "192.0.2.1")
+	}
+	if wait := LoginLockedFor("ada@example.com", "192.0.2.9"); wait <= 0 {
+		t.Error("account is not locked out from another IP address")
+	}
+	if wait := LoginLockedFor("bob@example.com", "192.0.2.1"); wait != 0 {
+		t.Errorf("IP address locked out after %d failures", accountFreeFailures)
+	}
+
+	// Logging in clears the account but not the IP address
+	RecordLoginSuccess("ada@example.com")
+	if wait := LoginLockedFor("ada@example.com", "192.0.2.1"); wait != 0 {
+		t.Errorf("still locked out for %v after a successful login", wait)
+	}
+	ip, _ := Throttle.Get(ipKey("192.0.2.1"))
+	if ip.Failures != accountFreeFailures {
+		t.Errorf("IP address has %d failures, want %d", ip.Failures, accountFreeFailures)
+	}
+
+	for i := accountFreeFailures; i < ipFreeFailures; i++ {
+		RecordLoginFailure(fmt.Sprintf("user%d@example.com", i), "192.0.2.1")
+	}
+	if wait := LoginLockedFor("carol@example.com", "192.0.2.1"); wait <= 0 {
+		t.Errorf("IP address is not locked out after %d failures", ipFreeFailures)
+	}
+}
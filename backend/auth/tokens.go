@@ -0,0 +1,91 @@
+// backend/auth/tokens.go
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidAccessToken is returned for access tokens that are malformed,
+// badly signed or expired
+var ErrInvalidAccessToken = errors.New("invalid or expired token")
+
+// AccessClaims is what an access token says about its holder
+type AccessClaims struct {
+	UserID    uint
+	SessionID uint
+}
+
+// issueAccessToken signs a short-lived access token for a user's session
+func issueAccessToken(userID, sessionID uint) (string, error) {
+	now := time.Now()
+	return signClaims(jwt.MapClaims{
+		"sub": userID,
+		"sid": sessionID,
+		"typ": "access",
+		"iat": now.Unix(),
+		"exp": now.Add(config.GetAccessTokenTTL()).Unix(),
+	})
+}
+
+// ParseAccessToken checks an access token's signature and expiry and returns
+// its claims. It does not check whether the session has been revoked.
+func ParseAccessToken(tokenString string) (AccessClaims, error) {
+	claims, err := parseClaims(tokenString, "access")
+	if err != nil {
+		return AccessClaims{}, ErrInvalidAccessToken
+	}
+
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return AccessClaims{}, ErrInvalidAccessToken
+	}
+	sid, ok := claims["sid"].(float64)
+	if !ok {
+		return AccessClaims{}, ErrInvalidAccessToken
+	}
+
+	return AccessClaims{UserID: uint(sub), SessionID: uint(sid)}, nil
+}
+
+// parseClaims checks the signature, expiry, issuer and audience of a JWT
+// signed by signClaims and that its "typ" claim is tokenType, so that one
+// kind of token cannot be passed off as another
+func parseClaims(tokenString, tokenType string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, verificationKey,
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(config.GetJWTIssuer()),
+		jwt.WithAudience(tokenAudience(tokenType)),
+	)
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != tokenType {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// NewToken returns a random URL-safe token
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex SHA-256 of a token, which is what gets stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
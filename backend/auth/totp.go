@@ -0,0 +1,135 @@
+// backend/auth/totp.go
+package auth
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer   = "Frigga"
+	totpPeriod   = 30 // seconds
+	totpSkew     = 1  // periods either side of now that are accepted
+	challengeTTL = 5 * time.Minute
+
+	recoveryCodeCount    = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// ErrInvalidChallengeToken is returned for two-factor challenge tokens that
+// are malformed, badly signed or expired
+var ErrInvalidChallengeToken = errors.New("invalid or expired challenge token")
+
+// NewTOTPKey generates a TOTP secret for a user's authenticator app
+func NewTOTPKey(user models.User) (*otp.Key, error) {
+	return totp.Generate(totp.GenerateOpts{
+		Issuer:      totpIssuer,
+		AccountName: user.Email,
+		Period:      totpPeriod,
+	})
+}
+
+// ValidateTOTP checks a code against a secret. It returns the time step the
+// code belongs to, which must be later than lastStep so that a code cannot be
+// replayed.
+func ValidateTOTP(secret, code string, lastStep int64) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	now := time.Now()
+	for offset := -totpSkew; offset <= totpSkew; offset++ {
+		at := now.Add(time.Duration(offset*totpPeriod) * time.Second)
+		expected, err := totp.GenerateCodeCustom(secret, at, totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false
+		}
+		step := at.Unix() / totpPeriod
+		if expected == code && step > lastStep {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes replaces a user's recovery codes with new ones and
+// returns them. They cannot be retrieved again.
+func GenerateRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Create(&models.RecoveryCode{UserID: userID, CodeHash: HashToken(code)}).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode reports whether the code is one of the user's unused
+// recovery codes, and uses it up if so
+func UseRecoveryCode(tx *gorm.DB, userID uint, code string) bool {
+	code = strings.ToLower(strings.TrimSpace(code))
+	result := tx.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, HashToken(code)).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// IssueChallengeToken signs a short-lived token that proves a user got their
+// password right and may now finish logging in with a second factor
+func IssueChallengeToken(userID uint) (string, error) {
+	now := time.Now()
+	return signClaims(jwt.MapClaims{
+		"sub": userID,
+		"typ": "2fa",
+		"iat": now.Unix(),
+		"exp": now.Add(challengeTTL).Unix(),
+	})
+}
+
+// ParseChallengeToken checks a challenge token and returns its user ID
+func ParseChallengeToken(tokenString string) (uint, error) {
+	claims, err := parseClaims(tokenString, "2fa")
+	if err != nil {
+		return 0, ErrInvalidChallengeToken
+	}
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return 0, ErrInvalidChallengeToken
+	}
+	return uint(sub), nil
+}
+
+// newRecoveryCode returns a random code like "k7m2p-9xq4r"
+func newRecoveryCode() (string, error) {
+	var b strings.Builder
+	for i := 0; i < 10; i++ {
+		if i == 5 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
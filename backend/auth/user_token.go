@@ -0,0 +1,66 @@
+// backend/auth/user_token.go
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidUserToken is returned for emailed tokens that are unknown,
+// expired, already used or meant for something else
+var ErrInvalidUserToken = errors.New("invalid or expired token")
+
+// CreateUserToken makes a single-use token for a user that expires after ttl.
+// Earlier unused tokens with the same purpose stop working.
+func CreateUserToken(userID uint, purpose models.TokenPurpose, ttl time.Duration) (string, error) {
+	token, err := NewToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.UserToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: HashToken(token),
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+	return token, err
+}
+
+// ConsumeUserToken checks a token and marks it used, returning the user it
+// was made for. Each token works once.
+func ConsumeUserToken(tx *gorm.DB, token string, purpose models.TokenPurpose) (models.User, error) {
+	var stored models.UserToken
+	err := tx.Where("token_hash = ? AND purpose = ?", HashToken(token), purpose).First(&stored).Error
+	if err != nil || stored.UsedAt != nil || !stored.ExpiresAt.After(time.Now()) {
+		return models.User{}, ErrInvalidUserToken
+	}
+
+	result := tx.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", stored.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return models.User{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.User{}, ErrInvalidUserToken
+	}
+
+	var user models.User
+	if err := tx.First(&user, stored.UserID).Error; err != nil {
+		return models.User{}, ErrInvalidUserToken
+	}
+	return user, nil
+}
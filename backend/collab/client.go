@@ -0,0 +1,116 @@
+// backend/collab/client.go
+package collab
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 1 << 20
+	sendBuffer     = 256
+)
+
+// Client is a single websocket connection editing a document
+type Client struct {
+	session *Session
+	conn    *websocket.Conn
+	user    models.User
+	canEdit bool
+	send    chan []byte
+}
+
+// push queues a message for the client. A client that cannot keep up is
+// disconnected; it will resynchronise when it reconnects.
+func (c *Client) push(msg message) {
+	select {
+	case c.send <- encode(msg):
+	default:
+		c.conn.Close()
+	}
+}
+
+// disconnect ends the connection once the messages queued for the client
+// are sent
+func (c *Client) disconnect() {
+	c.conn.SetReadDeadline(time.Now())
+}
+
+func (c *Client) readPump() {
+	defer c.conn.Close()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.reject("Invalid message")
+			continue
+		}
+
+		switch msg.Type {
+		case "operation":
+			if !c.canEdit {
+				c.reject("You are not authorized to edit this document")
+				continue
+			}
+			if msg.Operation == nil {
+				c.reject("Operation is required")
+				continue
+			}
+			if err := c.session.receive(c, msg.Revision, msg.Operation); err != nil {
+				c.reject(err.Error())
+			}
+		default:
+			c.reject("Unknown message type: " + msg.Type)
+		}
+	}
+}
+
+func (c *Client) reject(reason string) {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+	c.push(message{Type: "error", Revision: c.session.revision, Error: reason})
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
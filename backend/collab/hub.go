@@ -0,0 +1,116 @@
+// backend/collab/hub.go
+package collab
+
+import (
+	"sync"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gorilla/websocket"
+)
+
+// Hub keeps track of the live editing session of every open document
+type Hub struct {
+	mu       sync.Mutex
+	sessions map[uint]*Session
+}
+
+// DefaultHub is the hub used by the API
+var DefaultHub = NewHub()
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{sessions: make(map[uint]*Session)}
+}
+
+// Serve joins the connection to the document's session and relays operations
+// until the connection is closed. Read-only clients receive edits but cannot
+// make any.
+func (h *Hub) Serve(conn *websocket.Conn, document models.Document, user models.User, canEdit bool) {
+	client := &Client{
+		conn:    conn,
+		user:    user,
+		canEdit: canEdit,
+		send:    make(chan []byte, sendBuffer),
+	}
+	h.join(client, document)
+	go client.writePump()
+	client.readPump()
+	h.leave(client)
+}
+
+// Update runs write, which changes the stored content of a document through
+// another route such as a REST save, and brings the document's live session,
+// if there is one, up to date. Live edits not yet persisted are written
+// first, so a write based on an older revision conflicts rather than
+// discarding them, and no live edit is accepted while write runs. write
+// returns the content it stored, or false if it stored nothing.
+func (h *Hub) Update(documentID uint, write func() (string, bool, error)) error {
+	h.mu.Lock()
+	session := h.sessions[documentID]
+	h.mu.Unlock()
+
+	if session == nil {
+		_, _, err := write()
+		return err
+	}
+	return session.update(write)
+}
+
+func (h *Hub) join(client *Client, document models.Document) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	session, ok := h.sessions[document.ID]
+	if !ok {
+		session = newSession(h, document)
+		h.sessions[document.ID] = session
+	}
+	client.session = session
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.clients[client] = struct{}{}
+	if session.gone {
+		// The document was deleted since the client loaded it
+		client.push(message{Type: "error", Revision: session.revision, Error: errDeleted.Error()})
+		client.disconnect()
+		return
+	}
+	client.push(message{
+		Type:             "init",
+		Revision:         session.revision,
+		DocumentRevision: session.documentRevision,
+		Content:          session.content,
+		ReadOnly:         !client.canEdit,
+	})
+}
+
+func (h *Hub) leave(client *Client) {
+	session := client.session
+	session.mu.Lock()
+	delete(session.clients, client)
+	close(client.send)
+	empty := len(session.clients) == 0
+	session.mu.Unlock()
+
+	// Anyone reopening the document while this runs joins the same session,
+	// so it is persisted without holding up the hub. If persisting fails the
+	// session stays open and its persist loop keeps retrying.
+	if empty && session.persist(true) == nil {
+		h.release(session)
+	}
+}
+
+// release closes a session once nobody is connected and everything is persisted
+func (h *Hub) release(session *Session) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if len(session.clients) > 0 || len(session.unsaved) > 0 || h.sessions[session.documentID] != session {
+		return
+	}
+	close(session.stop)
+	delete(h.sessions, session.documentID)
+}
@@ -0,0 +1,258 @@
+// backend/collab/operation.go
+package collab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// component is a single step of an Operation: keep, insert or remove text
+type component struct {
+	retain int
+	insert string
+	delete int
+}
+
+// Operation is an operational transformation (OT) text operation. It walks
+// over the whole document and either retains, inserts or deletes characters,
+// counted in Unicode code points. On the wire it is encoded like ot.js: an
+// array where a positive number retains, a negative number deletes and a
+// string inserts.
+type Operation struct {
+	components   []component
+	baseLength   int
+	targetLength int
+}
+
+// Retain skips over n characters of the document
+func (o *Operation) Retain(n int) *Operation {
+	if n <= 0 {
+		return o
+	}
+	o.baseLength += n
+	o.targetLength += n
+	if last := len(o.components) - 1; last >= 0 && o.components[last].retain > 0 {
+		o.components[last].retain += n
+		return o
+	}
+	o.components = append(o.components, component{retain: n})
+	return o
+}
+
+// Insert inserts text at the current position
+func (o *Operation) Insert(text string) *Operation {
+	if text == "" {
+		return o
+	}
+	o.targetLength += utf8.RuneCountInString(text)
+
+	last := len(o.components) - 1
+	switch {
+	case last >= 0 && o.components[last].insert != "":
+		o.components[last].insert += text
+	case last >= 0 && o.components[last].delete > 0:
+		// Keep inserts in front of deletes so equivalent operations look the same
+		if last > 0 && o.components[last-1].insert != "" {
+			o.components[last-1].insert += text
+		} else {
+			o.components = append(o.components[:last], component{insert: text}, o.components[last])
+		}
+	default:
+		o.components = append(o.components, component{insert: text})
+	}
+	return o
+}
+
+// Delete removes n characters at the current position
+func (o *Operation) Delete(n int) *Operation {
+	if n <= 0 {
+		return o
+	}
+	o.baseLength += n
+	if last := len(o.components) - 1; last >= 0 && o.components[last].delete > 0 {
+		o.components[last].delete += n
+		return o
+	}
+	o.components = append(o.components, component{delete: n})
+	return o
+}
+
+// IsNoop reports whether applying the operation leaves the document unchanged
+func (o *Operation) IsNoop() bool {
+	return len(o.components) == 0 || (len(o.components) == 1 && o.components[0].retain > 0)
+}
+
+// Replace returns the operation that turns one text into another. Only the
+// part between their common prefix and suffix is replaced, so concurrent
+// edits elsewhere in the text transform past it untouched.
+func Replace(from, to string) *Operation {
+	a, b := []rune(from), []rune(to)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return new(Operation).
+		Retain(prefix).
+		Delete(len(a) - prefix - suffix).
+		Insert(string(b[prefix : len(b)-suffix])).
+		Retain(suffix)
+}
+
+// Apply applies the operation to a document
+func (o *Operation) Apply(document string) (string, error) {
+	runes := []rune(document)
+	if len(runes) != o.baseLength {
+		return "", fmt.Errorf("operation expects a document of length %d, got %d", o.baseLength, len(runes))
+	}
+
+	result := make([]rune, 0, o.targetLength)
+	position := 0
+	for _, c := range o.components {
+		switch {
+		case c.retain > 0:
+			result = append(result, runes[position:position+c.retain]...)
+			position += c.retain
+		case c.insert != "":
+			result = append(result, []rune(c.insert)...)
+		default:
+			position += c.delete
+		}
+	}
+	return string(result), nil
+}
+
+// Transform takes two operations a and b made concurrently on the same document
+// and returns a' and b' such that applying a then b' gives the same result as
+// applying b then a'.
+func Transform(a, b *Operation) (*Operation, *Operation, error) {
+	if a.baseLength != b.baseLength {
+		return nil, nil, errors.New("both operations have to have the same base length")
+	}
+
+	aPrime, bPrime := &Operation{}, &Operation{}
+	i, j := 0, 0
+	var op1, op2 component
+	has1, has2 := false, false
+	next1 := func() {
+		has1 = i < len(a.components)
+		if has1 {
+			op1 = a.components[i]
+			i++
+		}
+	}
+	next2 := func() {
+		has2 = j < len(b.components)
+		if has2 {
+			op2 = b.components[j]
+			j++
+		}
+	}
+	next1()
+	next2()
+
+	for has1 || has2 {
+		if has1 && op1.insert != "" {
+			aPrime.Insert(op1.insert)
+			bPrime.Retain(utf8.RuneCountInString(op1.insert))
+			next1()
+			continue
+		}
+		if has2 && op2.insert != "" {
+			aPrime.Retain(utf8.RuneCountInString(op2.insert))
+			bPrime.Insert(op2.insert)
+			next2()
+			continue
+		}
+		if !has1 || !has2 {
+			return nil, nil, errors.New("operations do not cover the same document")
+		}
+
+		length1, length2 := op1.retain+op1.delete, op2.retain+op2.delete
+		n := min(length1, length2)
+
+		switch {
+		case op1.retain > 0 && op2.retain > 0:
+			aPrime.Retain(n)
+			bPrime.Retain(n)
+		case op1.delete > 0 && op2.retain > 0:
+			aPrime.Delete(n)
+		case op1.retain > 0 && op2.delete > 0:
+			bPrime.Delete(n)
+		}
+		// When both delete the same text there is nothing left to do for either side
+
+		if length1 == n {
+			next1()
+		} else {
+			op1.retain, op1.delete = shrink(op1, n)
+		}
+		if length2 == n {
+			next2()
+		} else {
+			op2.retain, op2.delete = shrink(op2, n)
+		}
+	}
+
+	return aPrime, bPrime, nil
+}
+
+// shrink consumes n characters from a retain or delete component
+func shrink(c component, n int) (int, int) {
+	if c.retain > 0 {
+		return c.retain - n, 0
+	}
+	return 0, c.delete - n
+}
+
+// MarshalJSON encodes the operation in the ot.js wire format
+func (o *Operation) MarshalJSON() ([]byte, error) {
+	encoded := make([]interface{}, 0, len(o.components))
+	for _, c := range o.components {
+		switch {
+		case c.retain > 0:
+			encoded = append(encoded, c.retain)
+		case c.insert != "":
+			encoded = append(encoded, c.insert)
+		default:
+			encoded = append(encoded, -c.delete)
+		}
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes an operation from the ot.js wire format
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	var encoded []interface{}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	*o = Operation{}
+	for _, value := range encoded {
+		switch v := value.(type) {
+		case float64:
+			if v != float64(int(v)) || v == 0 {
+				return fmt.Errorf("invalid operation component: %v", v)
+			}
+			if v > 0 {
+				o.Retain(int(v))
+			} else {
+				o.Delete(int(-v))
+			}
+		case string:
+			if v == "" {
+				return errors.New("invalid operation component: empty insert")
+			}
+			o.Insert(v)
+		default:
+			return fmt.Errorf("invalid operation component: %v", value)
+		}
+	}
+	return nil
+}
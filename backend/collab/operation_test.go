@@ -0,0 +1,253 @@
+// backend/collab/operation_test.go
+package collab
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		document  string
+		operation *Operation
+		want      string
+	}{
+		{"insert", "hello", new(Operation).Retain(5).Insert(" world"), "hello world"},
+		{"delete", "hello world", new(Operation).Retain(5).Delete(6), "hello"},
+		{"replace", "cat", new(Operation).Delete(1).Insert("b").Retain(2), "bat"},
+		{"code points", "héllo", new(Operation).Retain(1).Delete(1).Insert("e").Retain(3), "hello"},
+		{"emoji", "a😀b", new(Operation).Retain(2).Insert("!").Retain(1), "a😀!b"},
+		{"empty document", "", new(Operation).Insert("x"), "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.operation.Apply(tt.document)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRejectsWrongLength(t *testing.T) {
+	operation := new(Operation).Retain(3).Insert("x")
+	if _, err := operation.Apply("ab"); err == nil {
+		t.Error("Apply to a shorter document succeeded")
+	}
+	if _, err := operation.Apply("abcd"); err == nil {
+		t.Error("Apply to a longer document succeeded")
+	}
+}
+
+func TestInsertBeforeDelete(t *testing.T) {
+	a := new(Operation).Delete(2).Insert("x")
+	b := new(Operation).Insert("x").Delete(2)
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	if string(aJSON) != string(bJSON) {
+		t.Errorf("equivalent operations encode differently: %s and %s", aJSON, bJSON)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{"hello world", "hello there world", `[6,"there ",5]`},
+		{"hello", "help", `[3,"p",-2]`},
+		{"aaa", "aa", `[2,-1]`},
+		{"", "new", `["new"]`},
+		{"old", "", `[-3]`},
+		{"same", "same", `[4]`},
+		{"héllo", "hello", `[1,"e",-1,3]`},
+	}
+
+	for _, tt := range tests {
+		operation := Replace(tt.from, tt.to)
+		if got, _ := json.Marshal(operation); string(got) != tt.want {
+			t.Errorf("Replace(%q, %q) = %s, want %s", tt.from, tt.to, got, tt.want)
+		}
+		if got, err := operation.Apply(tt.from); err != nil || got != tt.to {
+			t.Errorf("Replace(%q, %q) applies to %q (%v)", tt.from, tt.to, got, err)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		a, b     *Operation
+		want     string
+	}{
+		{
+			"inserts at different positions",
+			"abc",
+			new(Operation).Insert("x").Retain(3),
+			new(Operation).Retain(3).Insert("y"),
+			"xabcy",
+		},
+		{
+			"inserts at the same position put a first",
+			"abc",
+			new(Operation).Retain(1).Insert("x").Retain(2),
+			new(Operation).Retain(1).Insert("y").Retain(2),
+			"axybc",
+		},
+		{
+			"overlapping deletes",
+			"abcdef",
+			new(Operation).Retain(1).Delete(3).Retain(2),
+			new(Operation).Retain(2).Delete(3).Retain(1),
+			"af",
+		},
+		{
+			"insert inside a deleted range",
+			"abcdef",
+			new(Operation).Retain(1).Delete(4).Retain(1),
+			new(Operation).Retain(3).Insert("x").Retain(3),
+			"axf",
+		},
+		{
+			"same delete",
+			"abc",
+			new(Operation).Retain(1).Delete(1).Retain(1),
+			new(Operation).Retain(1).Delete(1).Retain(1),
+			"ac",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ab, ba := converge(t, tt.document, tt.a, tt.b)
+			if ab != tt.want {
+				t.Errorf("a then b' = %q, want %q", ab, tt.want)
+			}
+			if ba != tt.want {
+				t.Errorf("b then a' = %q, want %q", ba, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformRejectsDifferentBases(t *testing.T) {
+	a := new(Operation).Retain(3)
+	b := new(Operation).Retain(4)
+	if _, _, err := Transform(a, b); err == nil {
+		t.Error("Transform of operations on different documents succeeded")
+	}
+}
+
+// TestTransformConverges checks the transform property on random operations
+func TestTransformConverges(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		document := randomText(random, random.Intn(20))
+		a := randomOperation(random, document)
+		b := randomOperation(random, document)
+
+		ab, ba := converge(t, document, a, b)
+		if ab != ba {
+			aJSON, _ := json.Marshal(a)
+			bJSON, _ := json.Marshal(b)
+			t.Fatalf("%q with a=%s b=%s: a then b' = %q, b then a' = %q", document, aJSON, bJSON, ab, ba)
+		}
+	}
+}
+
+func TestOperationJSON(t *testing.T) {
+	var operation Operation
+	if err := json.Unmarshal([]byte(`[2,"xy",-1,3]`), &operation); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	got, err := operation.Apply("abcdef")
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got != "abxydef" {
+		t.Errorf("Apply = %q, want %q", got, "abxydef")
+	}
+
+	encoded, err := json.Marshal(&operation)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(encoded) != `[2,"xy",-1,3]` {
+		t.Errorf("Marshal = %s, want %s", encoded, `[2,"xy",-1,3]`)
+	}
+
+	for _, invalid := range []string{`[0]`, `[1.5]`, `[""]`, `[true]`, `{}`} {
+		if err := json.Unmarshal([]byte(invalid), &operation); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", invalid)
+		}
+	}
+}
+
+// converge transforms a and b against each other and returns the document
+// after a then b' and after b then a'
+func converge(t *testing.T, document string, a, b *Operation) (string, string) {
+	t.Helper()
+	aPrime, bPrime, err := Transform(a, b)
+	if err != nil {
+		t.Fatalf("Transform: %v", err)
+	}
+
+	afterA, err := a.Apply(document)
+	if err != nil {
+		t.Fatalf("Apply a: %v", err)
+	}
+	ab, err := bPrime.Apply(afterA)
+	if err != nil {
+		t.Fatalf("Apply b': %v", err)
+	}
+
+	afterB, err := b.Apply(document)
+	if err != nil {
+		t.Fatalf("Apply b: %v", err)
+	}
+	ba, err := aPrime.Apply(afterB)
+	if err != nil {
+		t.Fatalf("Apply a': %v", err)
+	}
+	return ab, ba
+}
+
+func randomText(random *rand.Rand, n int) string {
+	alphabet := []rune("abcdé😀 ")
+	text := make([]rune, n)
+	for i := range text {
+		text[i] = alphabet[random.Intn(len(alphabet))]
+	}
+	return string(text)
+}
+
+// randomOperation returns a random operation that applies to document
+func randomOperation(random *rand.Rand, document string) *Operation {
+	operation := &Operation{}
+	left := utf8.RuneCountInString(document)
+	for left > 0 {
+		n := 1 + random.Intn(left)
+		switch random.Intn(3) {
+		case 0:
+			operation.Retain(n)
+			left -= n
+		case 1:
+			operation.Delete(n)
+			left -= n
+		default:
+			operation.Insert(randomText(random, 1+random.Intn(3)))
+		}
+	}
+	if random.Intn(2) == 0 {
+		operation.Insert(randomText(random, 1+random.Intn(3)))
+	}
+	return operation
+}
@@ -0,0 +1,404 @@
+// backend/collab/session.go
+package collab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
+	"gorm.io/gorm"
+)
+
+const (
+	// persistInterval is how often a session writes its content to the database
+	persistInterval = 5 * time.Second
+	// snapshotInterval is how often a session records a models.Version while being edited
+	snapshotInterval = 2 * time.Minute
+	// maxHistory is how many operations a session keeps for transforming late operations
+	maxHistory = 1000
+	// maxMergeAttempts is how often a session merges in writes made outside it
+	// before giving up until the next persist
+	maxMergeAttempts = 3
+)
+
+var (
+	// errChanged is returned by store when someone else stored the document
+	// since the session last read or wrote it
+	errChanged = errors.New("the document was changed outside the session")
+	// errDeleted is sent to clients when their document is deleted
+	errDeleted = errors.New("the document was deleted")
+)
+
+// message is the JSON envelope exchanged with clients. Revision counts
+// operations within the session; DocumentRevision is the stored
+// models.Document revision, sent on "init" and on the "saved" message that
+// follows every write. Persisting live edits bumps the stored revision, so a
+// client that also saves over REST must use the latest DocumentRevision as
+// its If-Match or the save is rejected as a conflict.
+type message struct {
+	Type             string     `json:"type"`
+	Revision         int        `json:"revision"`
+	DocumentRevision uint       `json:"documentRevision,omitempty"`
+	Operation        *Operation `json:"operation,omitempty"`
+	Content          string     `json:"content,omitempty"`
+	ReadOnly         bool       `json:"readOnly,omitempty"`
+	UserID           uint       `json:"userId,omitempty"`
+	Error            string     `json:"error,omitempty"`
+}
+
+// Session holds the live state of a document while at least one client is
+// connected. Every operation is transformed against the operations the client
+// had not seen yet, applied to the content and relayed to the other clients.
+type Session struct {
+	documentID uint
+	db         *gorm.DB // limited to the document's workspace
+	hub        *Hub
+
+	// writeMu serialises writes of the content to the database, so an older
+	// state can never overwrite a newer one; it is taken before mu
+	writeMu sync.Mutex
+
+	mu           sync.Mutex
+	content      string
+	revision     int
+	history      []*Operation // history[k] took the document from revision historyStart+k to historyStart+k+1
+	historyStart int
+	clients      map[*Client]struct{}
+
+	persisted        string       // content as last read from or written to the document
+	unsaved          []*Operation // live edits since, which take persisted to content
+	gone             bool         // the document no longer exists, see closeIfGone
+	documentRevision uint         // revision of the stored document
+	snapshotBase     string       // content as of the last models.Version snapshot
+	lastEditorID     uint
+	lastSnapshot     time.Time
+
+	stop chan struct{}
+}
+
+func newSession(hub *Hub, document models.Document) *Session {
+	session := &Session{
+		documentID:       document.ID,
+		db:               config.DB.WithContext(tenancy.WithWorkspace(context.Background(), document.WorkspaceID)),
+		hub:              hub,
+		content:          document.Content,
+		persisted:        document.Content,
+		documentRevision: document.Revision,
+		clients:          make(map[*Client]struct{}),
+		snapshotBase:     document.Content,
+		lastSnapshot:     time.Now(),
+		stop:             make(chan struct{}),
+	}
+	go session.persistLoop()
+	return session
+}
+
+// receive applies an operation a client made against the given revision
+func (s *Session) receive(client *Client, revision int, operation *Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.gone {
+		return errDeleted
+	}
+	if revision < s.historyStart || revision > s.revision {
+		return errors.New("revision is out of range, reload the document")
+	}
+
+	var err error
+	for _, concurrent := range s.history[revision-s.historyStart:] {
+		if operation, _, err = Transform(operation, concurrent); err != nil {
+			return err
+		}
+	}
+
+	content, err := operation.Apply(s.content)
+	if err != nil {
+		return err
+	}
+
+	s.apply(content, operation)
+	s.unsaved = append(s.unsaved, operation)
+	s.lastEditorID = client.user.ID
+
+	client.push(message{Type: "ack", Revision: s.revision})
+	s.broadcast(client, message{Type: "operation", Revision: s.revision, Operation: operation, UserID: client.user.ID})
+	return nil
+}
+
+// update runs a write made outside the session, see Hub.Update
+func (s *Session) update(write func() (string, bool, error)) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.unsaved) > 0 {
+		err := s.store(s.content, s.documentRevision)
+		if err == nil {
+			s.stored(s.content, len(s.unsaved), s.documentRevision+1)
+		} else if errors.Is(err, errChanged) {
+			_, err = s.merge()
+		}
+		if err != nil {
+			s.closeIfGone(err)
+			return err
+		}
+	}
+
+	content, ok, err := write()
+	if err != nil {
+		return err
+	}
+
+	if ok && content != s.content {
+		// Send clients the operation that gets them to the stored content
+		operation := Replace(s.content, content)
+		s.apply(content, operation)
+
+		// The new content is already stored and versioned by whoever saved it
+		s.snapshotBase = content
+
+		s.broadcast(nil, message{Type: "operation", Revision: s.revision, Operation: operation})
+	}
+
+	if !ok {
+		return nil
+	}
+	var document models.Document
+	if err := s.db.Select("revision").First(&document, s.documentID).Error; err != nil {
+		return err
+	}
+	s.stored(content, 0, document.Revision)
+	return nil
+}
+
+// apply records an operation in the history; the caller must hold s.mu
+func (s *Session) apply(content string, operation *Operation) {
+	s.content = content
+	s.history = append(s.history, operation)
+	s.revision++
+	if len(s.history) > maxHistory {
+		dropped := len(s.history) - maxHistory
+		s.history = append([]*Operation(nil), s.history[dropped:]...)
+		s.historyStart += dropped
+	}
+}
+
+// broadcast sends a message to every client except the given one; the caller must hold s.mu
+func (s *Session) broadcast(except *Client, msg message) {
+	for client := range s.clients {
+		if client != except {
+			client.push(msg)
+		}
+	}
+}
+
+func (s *Session) persistLoop() {
+	ticker := time.NewTicker(persistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// A session left open because its last persist failed is
+			// released as soon as a retry succeeds
+			s.mu.Lock()
+			idle := len(s.clients) == 0
+			s.mu.Unlock()
+			if s.persist(idle) == nil && idle {
+				s.hub.release(s)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// persist writes the merged content to the document and, when a snapshot is
+// due or forced, records the state before the latest edits as a models.Version.
+// Live edits stay unsaved until they are written, so a failed write is
+// retried. A session whose document is gone has nothing left to persist.
+func (s *Session) persist(forceSnapshot bool) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.Lock()
+	if s.gone {
+		s.mu.Unlock()
+		return nil
+	}
+	snapshot := forceSnapshot || time.Since(s.lastSnapshot) >= snapshotInterval
+	content, pending, revision := s.content, len(s.unsaved), s.documentRevision
+	snapshotBase, editorID := s.snapshotBase, s.lastEditorID
+	s.mu.Unlock()
+
+	if pending > 0 {
+		err := s.store(content, revision)
+		s.mu.Lock()
+		if err == nil {
+			// Edits that arrived during the write are persisted next time
+			s.stored(content, pending, revision+1)
+		} else if errors.Is(err, errChanged) {
+			content, err = s.merge()
+		}
+		gone := s.closeIfGone(err)
+		s.mu.Unlock()
+		if gone {
+			return nil
+		}
+		if err != nil {
+			log.Printf("Failed to persist document %d: %v", s.documentID, err)
+			return err
+		}
+	}
+
+	if !snapshot || content == snapshotBase {
+		return nil
+	}
+
+	var document models.Document
+	if err := s.db.Select("title").First(&document, s.documentID).Error; err != nil {
+		s.mu.Lock()
+		gone := s.closeIfGone(err)
+		s.mu.Unlock()
+		if gone {
+			return nil
+		}
+		return err
+	}
+	version := models.Version{
+		DocumentID: s.documentID,
+		Title:      document.Title,
+		Content:    snapshotBase,
+		AuthorID:   editorID,
+	}
+	if err := s.db.Create(&version).Error; err != nil {
+		log.Printf("Failed to snapshot document %d: %v", s.documentID, err)
+		return err
+	}
+
+	s.mu.Lock()
+	s.snapshotBase = content
+	s.lastSnapshot = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// store writes content over the given revision of the document. It fails
+// with errChanged if someone else stored the document since, and with
+// gorm.ErrRecordNotFound if the document is gone. The caller must hold
+// s.writeMu.
+func (s *Session) store(content string, revision uint) error {
+	result := s.db.Model(&models.Document{}).
+		Where("id = ? AND revision = ?", s.documentID, revision).
+		Updates(map[string]interface{}{
+			"content":  content,
+			"revision": gorm.Expr("revision + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Document{}).Where("id = ?", s.documentID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return errChanged
+}
+
+// merge brings in content someone else stored since the session last read or
+// wrote the document, such as a REST save made before the session's first
+// persist. The change from the persisted content to the stored one is
+// transformed past the unsaved live edits like a late client operation and
+// sent to clients, then the merged content is stored. It returns the merged
+// content; the caller must hold s.writeMu and s.mu.
+func (s *Session) merge() (string, error) {
+	for attempt := 0; attempt < maxMergeAttempts; attempt++ {
+		var document models.Document
+		if err := s.db.Select("content", "revision").First(&document, s.documentID).Error; err != nil {
+			return "", err
+		}
+
+		operation := Replace(s.persisted, document.Content)
+		unsaved := make([]*Operation, len(s.unsaved))
+		for i, live := range s.unsaved {
+			var err error
+			if operation, unsaved[i], err = Transform(operation, live); err != nil {
+				return "", err
+			}
+		}
+		content, err := operation.Apply(s.content)
+		if err != nil {
+			return "", err
+		}
+
+		log.Printf("Merging a change made to document %d outside its live session", s.documentID)
+		if !operation.IsNoop() {
+			s.apply(content, operation)
+			s.broadcast(nil, message{Type: "operation", Revision: s.revision, Operation: operation})
+		}
+		s.persisted, s.unsaved, s.documentRevision = document.Content, unsaved, document.Revision
+		if len(s.unsaved) == 0 {
+			s.stored(content, 0, document.Revision)
+			return content, nil
+		}
+
+		err = s.store(content, document.Revision)
+		if errors.Is(err, errChanged) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		s.stored(content, len(s.unsaved), document.Revision+1)
+		return content, nil
+	}
+	return "", errChanged
+}
+
+// stored records that content, which includes the first count unsaved
+// operations, is now the given revision of the document and tells clients;
+// the caller must hold s.writeMu and s.mu
+func (s *Session) stored(content string, count int, revision uint) {
+	s.persisted = content
+	s.unsaved = s.unsaved[count:]
+	s.documentRevision = revision
+	s.broadcast(nil, message{Type: "saved", Revision: s.revision, DocumentRevision: s.documentRevision})
+}
+
+// closeIfGone ends the session if err says the document no longer exists,
+// as after it is moved to the trash. Clients are told and disconnected, and
+// live edits not yet persisted are dropped. The caller must hold s.mu.
+func (s *Session) closeIfGone(err error) bool {
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false
+	}
+	if !s.gone {
+		log.Printf("Document %d is gone, closing its live session with %d unsaved edits", s.documentID, len(s.unsaved))
+		s.gone = true
+		s.unsaved = nil
+		for client := range s.clients {
+			client.push(message{Type: "error", Revision: s.revision, Error: errDeleted.Error()})
+			client.disconnect()
+		}
+	}
+	return true
+}
+
+func encode(msg message) []byte {
+	data, _ := json.Marshal(msg)
+	return data
+}
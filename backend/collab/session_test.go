@@ -0,0 +1,132 @@
+// backend/collab/session_test.go
+package collab
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// startTestSession opens a session on a new document in workspace 1 with one
+// editing client, whose messages are left in its send channel
+func startTestSession(t *testing.T, content string) (*Session, *Client, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := tenancy.Register(db); err != nil {
+		t.Fatalf("register tenancy: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Document{}, &models.Version{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() { config.DB = previous })
+
+	db = db.WithContext(tenancy.WithWorkspace(context.Background(), 1))
+	author := models.User{Name: "Ada", Email: "ada@example.com", Password: "x"}
+	if err := db.Create(&author).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	document := models.Document{Title: "Plan", Content: content, AuthorID: author.ID}
+	if err := db.Create(&document).Error; err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	hub := NewHub()
+	session := newSession(hub, document)
+	hub.sessions[document.ID] = session
+	t.Cleanup(func() { hub.release(session) })
+
+	client := &Client{session: session, user: author, canEdit: true, send: make(chan []byte, sendBuffer)}
+	session.clients[client] = struct{}{}
+	return session, client, db
+}
+
+// received returns the types of the messages sent to the client so far
+func received(client *Client) []string {
+	var types []string
+	for {
+		select {
+		case data := <-client.send:
+			var msg message
+			json.Unmarshal(data, &msg)
+			types = append(types, msg.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func TestPersistMergesChangesMadeOutsideTheSession(t *testing.T) {
+	session, client, db := startTestSession(t, "hello world")
+
+	// Saved over REST after the session loaded the document
+	err := db.Model(&models.Document{}).Where("id = ?", session.documentID).Updates(map[string]interface{}{
+		"content":  "hello there world",
+		"revision": gorm.Expr("revision + 1"),
+	}).Error
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := session.receive(client, 0, new(Operation).Retain(11).Insert("!")); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+
+	if err := session.persist(false); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+
+	var document models.Document
+	db.First(&document, session.documentID)
+	if want := "hello there world!"; document.Content != want || session.content != want {
+		t.Errorf("stored %q, session has %q, want %q", document.Content, session.content, want)
+	}
+	if document.Revision != 3 || session.documentRevision != 3 {
+		t.Errorf("stored revision %d, session has %d, want 3", document.Revision, session.documentRevision)
+	}
+	if len(session.unsaved) != 0 {
+		t.Errorf("%d edits still unsaved", len(session.unsaved))
+	}
+	// The client is sent the change it had not seen
+	if got := received(client); len(got) != 3 || got[1] != "operation" || got[2] != "saved" {
+		t.Errorf("client received %v, want ack, operation and saved", got)
+	}
+}
+
+func TestPersistClosesSessionOfDeletedDocument(t *testing.T) {
+	session, client, db := startTestSession(t, "hello")
+	if err := session.receive(client, 0, new(Operation).Retain(5).Insert("!")); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	// The client has left, as a disconnected one would have
+	delete(session.clients, client)
+
+	db.Delete(&models.Document{}, session.documentID)
+	if err := session.persist(false); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if !session.gone || len(session.unsaved) != 0 {
+		t.Errorf("gone = %v with %d unsaved edits, want the session closed", session.gone, len(session.unsaved))
+	}
+	if err := session.receive(client, 1, new(Operation).Retain(6).Insert("?")); err != errDeleted {
+		t.Errorf("receive returned %v, want errDeleted", err)
+	}
+
+	session.hub.release(session)
+	if len(session.hub.sessions) != 0 {
+		t.Error("the session was not released")
+	}
+}
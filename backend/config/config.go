@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -24,6 +27,64 @@ func GetJWTSecret() string {
 	return secret
 }
 
+// GetJWTKeysDir returns the directory of RSA and Ed25519 keys tokens are
+// signed with. When it is empty, tokens are signed with JWT_SECRET instead.
+func GetJWTKeysDir() string {
+	return os.Getenv("JWT_KEYS_DIR")
+}
+
+// GetJWTSigningKeyID returns the kid of the key that signs new tokens, which
+// may be empty to use the newest key
+func GetJWTSigningKeyID() string {
+	return os.Getenv("JWT_SIGNING_KEY_ID")
+}
+
+// GetJWTIssuer returns the "iss" claim of the tokens this server signs.
+// Defaults to "frigga".
+func GetJWTIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "frigga"
+}
+
+// GetJWTAudience returns the "aud" claim of access tokens, which services
+// verifying them should insist on. Defaults to "frigga-api".
+func GetJWTAudience() string {
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		return audience
+	}
+	return "frigga-api"
+}
+
+// GetAccessTokenTTL returns how long an access token is valid for.
+// Defaults to 15 minutes.
+func GetAccessTokenTTL() time.Duration {
+	minutes := 15
+	if value := os.Getenv("ACCESS_TOKEN_TTL_MINUTES"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			log.Fatal("ACCESS_TOKEN_TTL_MINUTES must be a positive number of minutes")
+		}
+		minutes = parsed
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// GetRefreshTokenTTL returns how long a refresh token is valid for, which is
+// how long a user stays logged in without using the app. Defaults to 30 days.
+func GetRefreshTokenTTL() time.Duration {
+	days := 30
+	if value := os.Getenv("REFRESH_TOKEN_TTL_DAYS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			log.Fatal("REFRESH_TOKEN_TTL_DAYS must be a positive number of days")
+		}
+		days = parsed
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
 // GetDatabaseURL returns the database connection string
 func GetDatabaseURL() string {
 	dsn := os.Getenv("DATABASE_URL")
@@ -32,3 +93,149 @@ func GetDatabaseURL() string {
 	}
 	return dsn
 }
+
+// GetTrashRetention returns how long deleted documents stay in the trash
+// before they are purged. Defaults to 30 days.
+func GetTrashRetention() time.Duration {
+	days := 30
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			log.Fatal("TRASH_RETENTION_DAYS must be a non-negative number of days")
+		}
+		days = parsed
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// GetAllowedOrigins returns the browser origins allowed to call the API.
+// ALLOWED_ORIGINS can add more as a comma-separated list.
+func GetAllowedOrigins() []string {
+	origins := []string{
+		"http://localhost:3000",
+		"https://frigga-assignment.vercel.app", // Update with your actual Vercel URL
+	}
+	for _, origin := range strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// GetAppURL returns the base URL of the frontend, used for links in emails
+func GetAppURL() string {
+	if url := os.Getenv("APP_URL"); url != "" {
+		return strings.TrimSuffix(url, "/")
+	}
+	return "http://localhost:3000"
+}
+
+// GetMailDriver returns how email is delivered: "smtp", "file" or "log".
+// Defaults to "log".
+func GetMailDriver() string {
+	if driver := os.Getenv("MAIL_DRIVER"); driver != "" {
+		return driver
+	}
+	return "log"
+}
+
+// GetMailFrom returns the sender address of outgoing email
+func GetMailFrom() string {
+	if from := os.Getenv("MAIL_FROM"); from != "" {
+		return from
+	}
+	return "no-reply@localhost"
+}
+
+// GetMailDir returns the directory the file mail driver writes to
+func GetMailDir() string {
+	if dir := os.Getenv("MAIL_DIR"); dir != "" {
+		return dir
+	}
+	return "mail"
+}
+
+// GetSMTPAddr returns the host:port of the SMTP server
+func GetSMTPAddr() string {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Fatal("SMTP_HOST environment variable not set")
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	return host + ":" + port
+}
+
+// GetSMTPAuth returns the SMTP username and password, which may be empty
+func GetSMTPAuth() (string, string) {
+	return os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD")
+}
+
+// OIDCEnabled reports whether single sign-on with an OpenID Connect provider
+// is configured
+func OIDCEnabled() bool {
+	return os.Getenv("OIDC_ISSUER_URL") != ""
+}
+
+// GetOIDCIssuerURL returns the issuer URL of the OpenID Connect provider
+func GetOIDCIssuerURL() string {
+	return os.Getenv("OIDC_ISSUER_URL")
+}
+
+// GetOIDCClient returns the client ID and secret registered with the OpenID
+// Connect provider
+func GetOIDCClient() (string, string) {
+	clientID := os.Getenv("OIDC_CLIENT_ID")
+	if clientID == "" {
+		log.Fatal("OIDC_CLIENT_ID environment variable not set")
+	}
+	return clientID, os.Getenv("OIDC_CLIENT_SECRET")
+}
+
+// GetOIDCRedirectURL returns the URL the provider sends users back to after
+// they sign in, which must point at /api/auth/oidc/callback
+func GetOIDCRedirectURL() string {
+	redirectURL := os.Getenv("OIDC_REDIRECT_URL")
+	if redirectURL == "" {
+		log.Fatal("OIDC_REDIRECT_URL environment variable not set")
+	}
+	return redirectURL
+}
+
+// GetLoginThrottleStore returns where failed login counts are kept: memory
+// (the default) or database
+func GetLoginThrottleStore() string {
+	if store := os.Getenv("LOGIN_THROTTLE_STORE"); store != "" {
+		return store
+	}
+	return "memory"
+}
+
+// GetAdminEmails returns the users listed in ADMIN_EMAILS, who are made
+// admins at startup once they have verified their email
+func GetAdminEmails() []string {
+	emails := []string{}
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// GetTrustedProxies returns the reverse proxies, as IPs or CIDR ranges, whose
+// X-Forwarded-For header is believed when working out a client's IP. With
+// none set, the address of the connection is used, so clients cannot pick
+// their own IP by sending the header.
+func GetTrustedProxies() []string {
+	proxies := []string{}
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
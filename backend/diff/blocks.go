@@ -0,0 +1,234 @@
+// backend/diff/blocks.go
+package diff
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// blockElements are the HTML elements the editor uses to lay out a document
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Blockquote: true, atom.Pre: true, atom.Hr: true,
+	atom.Div: true, atom.Table: true, atom.Thead: true, atom.Tbody: true, atom.Tr: true, atom.Td: true, atom.Th: true,
+	atom.Figure: true, atom.Img: true,
+}
+
+// block is the smallest unit of a document compared as a whole
+type block struct {
+	tag  string // block ancestors of the block including itself, e.g. "ul>li>p"
+	html string
+	text string
+}
+
+func (b block) key() string {
+	return b.tag + "\x00" + b.html
+}
+
+// Blocks computes a block-level diff between two HTML documents. Blocks that
+// were reordered are reported as moves, and blocks that were edited in place
+// carry a word-level diff of their text.
+func Blocks(oldHTML, newHTML string) []BlockChange {
+	oldBlocks := splitBlocks(oldHTML)
+	newBlocks := splitBlocks(newHTML)
+
+	pairs := lcs(len(oldBlocks), len(newBlocks), func(i, j int) bool {
+		return oldBlocks[i].key() == newBlocks[j].key()
+	})
+
+	// Blocks removed in one place and added unchanged in another were moved
+	oldMatched := make(map[int]bool)
+	newMatched := make(map[int]bool)
+	for _, pair := range pairs {
+		oldMatched[pair[0]] = true
+		newMatched[pair[1]] = true
+	}
+	inserted := make(map[string][]int)
+	for j, b := range newBlocks {
+		if !newMatched[j] {
+			inserted[b.key()] = append(inserted[b.key()], j)
+		}
+	}
+	movedTo := make(map[int]int)   // old index -> new index
+	movedFrom := make(map[int]int) // new index -> old index
+	for i, b := range oldBlocks {
+		if candidates := inserted[b.key()]; !oldMatched[i] && len(candidates) > 0 {
+			movedTo[i] = candidates[0]
+			movedFrom[candidates[0]] = i
+			inserted[b.key()] = candidates[1:]
+		}
+	}
+
+	changes := []BlockChange{}
+	i, j := 0, 0
+	for _, pair := range append(pairs, [2]int{len(oldBlocks), len(newBlocks)}) {
+		changes = append(changes, hunk(oldBlocks, newBlocks, i, pair[0], j, pair[1], movedTo, movedFrom)...)
+		i, j = pair[0], pair[1]
+
+		if i < len(oldBlocks) && j < len(newBlocks) {
+			changes = append(changes, BlockChange{
+				Op:       Equal,
+				Tag:      newBlocks[j].tag,
+				OldIndex: i,
+				NewIndex: j,
+				NewHTML:  newBlocks[j].html,
+			})
+			i++
+			j++
+		}
+	}
+
+	return changes
+}
+
+// hunk describes the changes between two consecutive unchanged blocks, i.e.
+// old blocks [oldStart, oldEnd) were replaced by new blocks [newStart, newEnd)
+func hunk(oldBlocks, newBlocks []block, oldStart, oldEnd, newStart, newEnd int, movedTo, movedFrom map[int]int) []BlockChange {
+	var removed, added []int
+	for i := oldStart; i < oldEnd; i++ {
+		if _, ok := movedTo[i]; !ok {
+			removed = append(removed, i)
+		}
+	}
+	for j := newStart; j < newEnd; j++ {
+		if _, ok := movedFrom[j]; !ok {
+			added = append(added, j)
+		}
+	}
+
+	// Pair removed and added blocks of the same kind as in-place edits
+	modifiedFrom := make(map[int]int)
+	var changes []BlockChange
+	for k, i := range removed {
+		if k < len(added) && oldBlocks[i].tag == newBlocks[added[k]].tag {
+			modifiedFrom[added[k]] = i
+			continue
+		}
+		changes = append(changes, BlockChange{
+			Op:       Delete,
+			Tag:      oldBlocks[i].tag,
+			OldIndex: i,
+			NewIndex: -1,
+			OldHTML:  oldBlocks[i].html,
+		})
+	}
+
+	for j := newStart; j < newEnd; j++ {
+		if i, ok := movedFrom[j]; ok {
+			changes = append(changes, BlockChange{
+				Op:       Move,
+				Tag:      newBlocks[j].tag,
+				OldIndex: i,
+				NewIndex: j,
+				NewHTML:  newBlocks[j].html,
+			})
+		} else if i, ok := modifiedFrom[j]; ok {
+			changes = append(changes, BlockChange{
+				Op:       Modify,
+				Tag:      newBlocks[j].tag,
+				OldIndex: i,
+				NewIndex: j,
+				OldHTML:  oldBlocks[i].html,
+				NewHTML:  newBlocks[j].html,
+				Words:    Words(oldBlocks[i].text, newBlocks[j].text),
+			})
+		} else {
+			changes = append(changes, BlockChange{
+				Op:       Insert,
+				Tag:      newBlocks[j].tag,
+				OldIndex: -1,
+				NewIndex: j,
+				NewHTML:  newBlocks[j].html,
+			})
+		}
+	}
+
+	return changes
+}
+
+// splitBlocks breaks an HTML document into its innermost block elements.
+// Inline content that sits next to block elements becomes a block of its own.
+func splitBlocks(content string) []block {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(content), body)
+	if err != nil {
+		// Fall back to comparing the raw content as a single block
+		return []block{{tag: "body", html: content, text: content}}
+	}
+
+	var blocks []block
+	collect(nodes, "", &blocks)
+	return blocks
+}
+
+func collect(nodes []*html.Node, path string, blocks *[]block) {
+	var inline []*html.Node
+	flush := func() {
+		if b, ok := newBlock(inline, path+"text"); ok {
+			*blocks = append(*blocks, b)
+		}
+		inline = nil
+	}
+
+	for _, node := range nodes {
+		if node.Type != html.ElementNode || !blockElements[node.DataAtom] {
+			inline = append(inline, node)
+			continue
+		}
+
+		flush()
+		if containsBlock(node) {
+			collect(children(node), path+node.Data+">", blocks)
+		} else if b, ok := newBlock([]*html.Node{node}, path+node.Data); ok {
+			*blocks = append(*blocks, b)
+		}
+	}
+	flush()
+}
+
+func newBlock(nodes []*html.Node, tag string) (block, bool) {
+	var markup, text strings.Builder
+	for _, node := range nodes {
+		html.Render(&markup, node)
+		text.WriteString(textContent(node))
+	}
+
+	// Skip whitespace between blocks, but keep empty elements like <hr>
+	if len(nodes) == 1 && nodes[0].Type == html.ElementNode {
+		return block{tag: tag, html: markup.String(), text: text.String()}, true
+	}
+	if strings.TrimSpace(markup.String()) == "" {
+		return block{}, false
+	}
+	return block{tag: tag, html: markup.String(), text: text.String()}, true
+}
+
+func containsBlock(node *html.Node) bool {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && (blockElements[child.DataAtom] || containsBlock(child)) {
+			return true
+		}
+	}
+	return false
+}
+
+func children(node *html.Node) []*html.Node {
+	var nodes []*html.Node
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		nodes = append(nodes, child)
+	}
+	return nodes
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var text strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		text.WriteString(textContent(child))
+	}
+	return text.String()
+}
@@ -0,0 +1,140 @@
+// backend/diff/diff.go
+package diff
+
+// Operation describes how a piece of content changed between two revisions
+type Operation string
+
+const (
+	Equal  Operation = "equal"
+	Insert Operation = "insert"
+	Delete Operation = "delete"
+	Modify Operation = "modify"
+	Move   Operation = "move"
+)
+
+// WordChange is a run of text that was kept, inserted or deleted
+type WordChange struct {
+	Op   Operation `json:"op"`
+	Text string    `json:"text"`
+}
+
+// BlockChange describes what happened to a single block (paragraph, heading,
+// list item, ...) of the document. OldIndex and NewIndex are positions in the
+// block lists of the two revisions, or -1 when the block only exists on one side.
+type BlockChange struct {
+	Op       Operation    `json:"op"`
+	Tag      string       `json:"tag"`
+	OldIndex int          `json:"oldIndex"`
+	NewIndex int          `json:"newIndex"`
+	OldHTML  string       `json:"oldHtml,omitempty"`
+	NewHTML  string       `json:"newHtml,omitempty"`
+	Words    []WordChange `json:"words,omitempty"`
+}
+
+// Stats summarises a diff
+type Stats struct {
+	Insertions    int `json:"insertions"`
+	Deletions     int `json:"deletions"`
+	Modifications int `json:"modifications"`
+	Moves         int `json:"moves"`
+}
+
+// Result is the full change set between two revisions of a document
+type Result struct {
+	Title  []WordChange  `json:"title"`
+	Blocks []BlockChange `json:"blocks"`
+	Stats  Stats         `json:"stats"`
+}
+
+// Documents compares two revisions of a document, given as title and HTML content
+func Documents(oldTitle, oldContent, newTitle, newContent string) Result {
+	blocks := Blocks(oldContent, newContent)
+
+	var stats Stats
+	for _, block := range blocks {
+		switch block.Op {
+		case Insert:
+			stats.Insertions++
+		case Delete:
+			stats.Deletions++
+		case Modify:
+			stats.Modifications++
+		case Move:
+			stats.Moves++
+		}
+	}
+
+	return Result{
+		Title:  Words(oldTitle, newTitle),
+		Blocks: blocks,
+		Stats:  stats,
+	}
+}
+
+// maxLCSCells caps the size of the table lcs fills in, which grows with the
+// product of the two lengths, so a huge document cannot exhaust the server's
+// memory
+const maxLCSCells = 1 << 20
+
+// lcs computes the pairs of indexes of a longest common subsequence of two
+// sequences of length n and m, where equal(i, j) compares their elements.
+// The common prefix and suffix are matched first; when what is left between
+// them is still too large to compare, none of it is matched, so it shows up
+// as replaced as a whole.
+func lcs(n, m int, equal func(i, j int) bool) [][2]int {
+	var pairs [][2]int
+	start := 0
+	for start < n && start < m && equal(start, start) {
+		pairs = append(pairs, [2]int{start, start})
+		start++
+	}
+	suffix := 0
+	for suffix < n-start && suffix < m-start && equal(n-1-suffix, m-1-suffix) {
+		suffix++
+	}
+
+	rows, cols := n-start-suffix, m-start-suffix
+	if rows > 0 && cols > 0 && rows*cols <= maxLCSCells {
+		pairs = append(pairs, lcsTable(rows, cols, func(i, j int) bool {
+			return equal(start+i, start+j)
+		}, start)...)
+	}
+
+	for k := suffix; k > 0; k-- {
+		pairs = append(pairs, [2]int{n - k, m - k})
+	}
+	return pairs
+}
+
+// lcsTable finds a longest common subsequence by dynamic programming and
+// returns its pairs shifted by offset
+func lcsTable(n, m int, equal func(i, j int) bool, offset int) [][2]int {
+	lengths := make([][]int32, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if equal(i, j) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var pairs [][2]int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case equal(i, j):
+			pairs = append(pairs, [2]int{offset + i, offset + j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
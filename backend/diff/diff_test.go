@@ -0,0 +1,187 @@
+// backend/diff/diff_test.go
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []WordChange
+	}{
+		{
+			"unchanged",
+			"hello world", "hello world",
+			[]WordChange{{Equal, "hello world"}},
+		},
+		{
+			"replaced word",
+			"the quick fox", "the slow fox",
+			[]WordChange{{Equal, "the "}, {Delete, "quick"}, {Insert, "slow"}, {Equal, " fox"}},
+		},
+		{
+			"punctuation is a word of its own",
+			"Hello, world", "Hello! world",
+			[]WordChange{{Equal, "Hello"}, {Delete, ","}, {Insert, "!"}, {Equal, " world"}},
+		},
+		{
+			"from empty",
+			"", "new text",
+			[]WordChange{{Insert, "new text"}},
+		},
+		{
+			"to empty",
+			"old text", "",
+			[]WordChange{{Delete, "old text"}},
+		},
+		{
+			"both empty",
+			"", "",
+			[]WordChange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Words(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
+
+// blockOp is the part of a BlockChange the block tests compare
+type blockOp struct {
+	Op       Operation
+	Tag      string
+	OldIndex int
+	NewIndex int
+}
+
+func TestBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []blockOp
+	}{
+		{
+			"unchanged",
+			"<p>a</p><p>b</p>", "<p>a</p><p>b</p>",
+			[]blockOp{{Equal, "p", 0, 0}, {Equal, "p", 1, 1}},
+		},
+		{
+			"inserted",
+			"<p>a</p><p>c</p>", "<p>a</p><h2>b</h2><p>c</p>",
+			[]blockOp{{Equal, "p", 0, 0}, {Insert, "h2", -1, 1}, {Equal, "p", 1, 2}},
+		},
+		{
+			"deleted",
+			"<p>a</p><p>b</p><p>c</p>", "<p>a</p><p>c</p>",
+			[]blockOp{{Equal, "p", 0, 0}, {Delete, "p", 1, -1}, {Equal, "p", 2, 1}},
+		},
+		{
+			"modified in place",
+			"<p>a</p><p>old text</p>", "<p>a</p><p>new text</p>",
+			[]blockOp{{Equal, "p", 0, 0}, {Modify, "p", 1, 1}},
+		},
+		{
+			"different kinds of block are not a modification",
+			"<p>a</p>", "<h1>a</h1>",
+			[]blockOp{{Delete, "p", 0, -1}, {Insert, "h1", -1, 0}},
+		},
+		{
+			"moved",
+			"<p>a</p><p>b</p><p>c</p>", "<p>b</p><p>c</p><p>a</p>",
+			[]blockOp{{Equal, "p", 1, 0}, {Equal, "p", 2, 1}, {Move, "p", 0, 2}},
+		},
+		{
+			"nested list items",
+			"<ul><li>one</li><li>two</li></ul>", "<ul><li>one</li><li>three</li></ul>",
+			[]blockOp{{Equal, "ul>li", 0, 0}, {Modify, "ul>li", 1, 1}},
+		},
+		{
+			"inline content next to blocks",
+			"intro<p>a</p>", "intro<p>a</p>outro",
+			[]blockOp{{Equal, "text", 0, 0}, {Equal, "p", 1, 1}, {Insert, "text", -1, 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []blockOp{}
+			for _, change := range Blocks(tt.old, tt.new) {
+				got = append(got, blockOp{change.Op, change.Tag, change.OldIndex, change.NewIndex})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Blocks(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlocksModifyHasWordDiff(t *testing.T) {
+	changes := Blocks("<p>the quick fox</p>", "<p>the slow fox</p>")
+	if len(changes) != 1 || changes[0].Op != Modify {
+		t.Fatalf("Blocks = %v, want a single modification", changes)
+	}
+	if changes[0].OldHTML != "<p>the quick fox</p>" || changes[0].NewHTML != "<p>the slow fox</p>" {
+		t.Errorf("modification HTML = %q -> %q", changes[0].OldHTML, changes[0].NewHTML)
+	}
+	want := []WordChange{{Equal, "the "}, {Delete, "quick"}, {Insert, "slow"}, {Equal, " fox"}}
+	if !reflect.DeepEqual(changes[0].Words, want) {
+		t.Errorf("Words = %v, want %v", changes[0].Words, want)
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	result := Documents(
+		"Plan", "<p>a</p><p>b</p><p>c</p><p>d</p>",
+		"Final plan", "<p>b</p><p>c</p><p>a</p><p>e</p><h2>f</h2>",
+	)
+
+	want := Stats{Insertions: 1, Deletions: 0, Modifications: 1, Moves: 1}
+	if result.Stats != want {
+		t.Errorf("Stats = %+v, want %+v", result.Stats, want)
+	}
+	wantTitle := []WordChange{{Delete, "Plan"}, {Insert, "Final plan"}}
+	if !reflect.DeepEqual(result.Title, wantTitle) {
+		t.Errorf("Title = %v, want %v", result.Title, wantTitle)
+	}
+}
+
+func TestWordsOnLargeText(t *testing.T) {
+	var oldText, newText strings.Builder
+	oldText.WriteString("intro ")
+	newText.WriteString("intro ")
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&oldText, "old%d ", i)
+		fmt.Fprintf(&newText, "new%d ", i)
+	}
+	oldText.WriteString("outro")
+	newText.WriteString("outro")
+
+	changes := Words(oldText.String(), newText.String())
+
+	// Too large to compare word by word, the middle is replaced as a whole
+	// but the text on both sides still adds up
+	var oldJoined, newJoined strings.Builder
+	for _, change := range changes {
+		if change.Op != Insert {
+			oldJoined.WriteString(change.Text)
+		}
+		if change.Op != Delete {
+			newJoined.WriteString(change.Text)
+		}
+	}
+	if oldJoined.String() != oldText.String() || newJoined.String() != newText.String() {
+		t.Error("changes do not add up to the two texts")
+	}
+	if len(changes) != 4 || changes[0] != (WordChange{Equal, "intro "}) || changes[3] != (WordChange{Equal, " outro"}) {
+		t.Errorf("got %d changes starting with %v, want the common start and end kept", len(changes), changes[0])
+	}
+}
@@ -0,0 +1,44 @@
+// backend/diff/words.go
+package diff
+
+import "regexp"
+
+// wordPattern splits text into words, runs of whitespace and single punctuation marks
+var wordPattern = regexp.MustCompile(`\s+|[\p{L}\p{N}_]+|[^\s\p{L}\p{N}_]`)
+
+// Words computes a word-level diff between two pieces of plain text.
+// Adjacent changes of the same kind are merged into a single run.
+func Words(oldText, newText string) []WordChange {
+	oldWords := wordPattern.FindAllString(oldText, -1)
+	newWords := wordPattern.FindAllString(newText, -1)
+
+	pairs := lcs(len(oldWords), len(newWords), func(i, j int) bool {
+		return oldWords[i] == newWords[j]
+	})
+
+	changes := []WordChange{}
+	add := func(op Operation, text string) {
+		if last := len(changes) - 1; last >= 0 && changes[last].Op == op {
+			changes[last].Text += text
+			return
+		}
+		changes = append(changes, WordChange{Op: op, Text: text})
+	}
+
+	i, j := 0, 0
+	for _, pair := range append(pairs, [2]int{len(oldWords), len(newWords)}) {
+		for ; i < pair[0]; i++ {
+			add(Delete, oldWords[i])
+		}
+		for ; j < pair[1]; j++ {
+			add(Insert, newWords[j])
+		}
+		if i < len(oldWords) && j < len(newWords) {
+			add(Equal, oldWords[i])
+			i++
+			j++
+		}
+	}
+
+	return changes
+}
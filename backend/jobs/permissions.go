@@ -0,0 +1,62 @@
+// backend/jobs/permissions.go
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"gorm.io/gorm"
+)
+
+// permissionSweepInterval is how often expired permissions are cleaned up
+const permissionSweepInterval = 5 * time.Minute
+
+// StartPermissionSweeper runs SweepExpiredPermissions in the background on a fixed interval
+func StartPermissionSweeper() {
+	go func() {
+		ticker := time.NewTicker(permissionSweepInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := SweepExpiredPermissions(); err != nil {
+				log.Printf("Failed to sweep expired permissions: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// SweepExpiredPermissions deletes permissions past their expiry and records
+// each one in the document's permission history
+func SweepExpiredPermissions() error {
+	var expired []models.Permission
+	err := config.DB.Where("expires_at IS NOT NULL AND expires_at <= ?", time.Now()).Find(&expired).Error
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range expired {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Delete(&models.Permission{}, permission.ID).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.PermissionEvent{
+				DocumentID: permission.DocumentID,
+				UserID:     &permission.UserID,
+				Action:     models.PermissionExpired,
+				Level:      permission.Level,
+				ExpiresAt:  permission.ExpiresAt,
+			}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(expired) > 0 {
+		log.Printf("Removed %d expired permission(s)", len(expired))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// backend/jobs/trash.go
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
+	"gorm.io/gorm"
+)
+
+// trashPurgeInterval is how often the trash is checked for expired documents
+const trashPurgeInterval = time.Hour
+
+// StartTrashPurger runs PurgeTrash in the background on a fixed interval
+func StartTrashPurger() {
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := PurgeTrash(config.GetTrashRetention()); err != nil {
+				log.Printf("Failed to purge trash: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// PurgeTrash permanently deletes documents that have been in the trash for
+// longer than the retention period, along with their versions and permissions.
+func PurgeTrash(retention time.Duration) error {
+	cutoff := time.Now().Add(-retention)
+	// The trash of every workspace is purged
+	db := config.DB.WithContext(tenancy.AllWorkspaces(context.Background()))
+
+	var documentIDs []uint
+	err := db.Unscoped().Model(&models.Document{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &documentIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, id := range documentIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var document models.Document
+			if err := tx.Unscoped().First(&document, id).Error; err != nil {
+				return err
+			}
+
+			// Child pages take the place of the purged page in the tree
+			err := tx.Unscoped().Model(&models.Document{}).Where("parent_id = ?", id).Update("parent_id", document.ParentID).Error
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Unscoped().Where("document_id = ?", id).Delete(&models.Version{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("document_id = ?", id).Delete(&models.Permission{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("document_id = ?", id).Delete(&models.GroupPermission{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("document_id = ?", id).Delete(&models.PermissionEvent{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("document_id = ?", id).Delete(&models.AccessRequest{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("document_id = ?", id).Delete(&models.ShareLink{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Notification{}).Where("document_id = ?", id).Update("document_id", nil).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(&models.Document{}, id).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(documentIDs) > 0 {
+		log.Printf("Purged %d document(s) from the trash", len(documentIDs))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// backend/mailer/mailer.go
+package mailer
+
+import (
+	"log"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Default is the mailer used by Send. It is set up from the environment by
+// Configure.
+var Default Mailer = LogMailer{}
+
+// Configure sets Default to the mailer chosen by MAIL_DRIVER
+func Configure() {
+	switch driver := config.GetMailDriver(); driver {
+	case "smtp":
+		username, password := config.GetSMTPAuth()
+		Default = SMTPMailer{
+			Addr:     config.GetSMTPAddr(),
+			Username: username,
+			Password: password,
+			From:     config.GetMailFrom(),
+		}
+	case "file":
+		Default = FileMailer{Dir: config.GetMailDir(), From: config.GetMailFrom()}
+	case "log":
+		Default = LogMailer{}
+	default:
+		log.Fatalf("Unknown MAIL_DRIVER %q, expected smtp, file or log", driver)
+	}
+}
+
+// Send delivers a message with the default mailer
+func Send(msg Message) error {
+	return Default.Send(msg)
+}
+
+// SendAsync delivers a message in the background so the request does not wait
+// on the mail server; failures are logged
+func SendAsync(msg Message) {
+	go func() {
+		if err := Send(msg); err != nil {
+			log.Printf("Failed to send %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
@@ -0,0 +1,158 @@
+// backend/mailer/mailer_test.go
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// smtpDelivery is one email received by testSMTPServer
+type smtpDelivery struct {
+	from string
+	to   []string
+	data string
+}
+
+// testSMTPServer accepts email on a local port and hands each delivery to
+// the returned channel. It speaks just enough SMTP for net/smtp.
+func testSMTPServer(t *testing.T) (string, <-chan smtpDelivery) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	deliveries := make(chan smtpDelivery, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, deliveries)
+		}
+	}()
+	return listener.Addr().String(), deliveries
+}
+
+func serveSMTP(conn net.Conn, deliveries chan<- smtpDelivery) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var delivery smtpDelivery
+	reply("220 localhost ESMTP test")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		switch verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			delivery.from = command
+			reply("250 OK")
+		case "RCPT":
+			delivery.to = append(delivery.to, command)
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			delivery.data = data.String()
+			deliveries <- delivery
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	addr, deliveries := testSMTPServer(t)
+	mailer := SMTPMailer{Addr: addr, From: "noreply@example.com"}
+
+	err := mailer.Send(Message{
+		To:      "ada@example.com",
+		Subject: "Reset your password\r\nBcc: mallory@example.com",
+		Body:    "Open this link:\nhttp://localhost:3000/reset-password?token=abc",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	delivery := <-deliveries
+	if delivery.from != "MAIL FROM:<noreply@example.com>" {
+		t.Errorf("sender = %q", delivery.from)
+	}
+	if len(delivery.to) != 1 || delivery.to[0] != "RCPT TO:<ada@example.com>" {
+		t.Errorf("recipients = %q", delivery.to)
+	}
+
+	for _, want := range []string{
+		"From: noreply@example.com\r\n",
+		"To: ada@example.com\r\n",
+		"Subject: Reset your passwordBcc: mallory@example.com\r\n",
+		"\r\n\r\nOpen this link:\r\nhttp://localhost:3000/reset-password?token=abc",
+	} {
+		if !strings.Contains(delivery.data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, delivery.data)
+		}
+	}
+	if strings.Contains(delivery.data, "\r\nBcc:") {
+		t.Error("the subject added a header of its own")
+	}
+}
+
+func TestSMTPMailerUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	if err := (SMTPMailer{Addr: addr, From: "noreply@example.com"}).Send(Message{To: "ada@example.com"}); err == nil {
+		t.Error("Send to a closed port succeeded")
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer := FileMailer{Dir: dir, From: "noreply@example.com"}
+
+	if err := mailer.Send(Message{To: "ada@example.com", Subject: "Verify your email", Body: "Hello"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*ada_at_example.com.eml"))
+	if len(files) != 1 {
+		t.Fatalf("found %d emails, want 1", len(files))
+	}
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("read email: %v", err)
+	}
+	if !strings.Contains(string(content), "Subject: Verify your email\r\n") || !strings.HasSuffix(string(content), "\r\n\r\nHello") {
+		t.Errorf("unexpected email:\n%s", content)
+	}
+}
@@ -0,0 +1,29 @@
+// backend/mailer/smtp.go
+package mailer
+
+import (
+	"net"
+	"net/smtp"
+)
+
+// SMTPMailer sends email through an SMTP server. Authentication is only used
+// when a username is set.
+type SMTPMailer struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers a message through the SMTP server
+func (m SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, err := net.SplitHostPort(m.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+	return smtp.SendMail(m.Addr, auth, m.From, []string{msg.To}, format(m.From, msg))
+}
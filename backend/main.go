@@ -7,9 +7,13 @@ import (
 	"strconv"
 
 	"github.com/Devashish08/frigga-assigment/backend/api"
+	"github.com/Devashish08/frigga-assigment/backend/auth"
 	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/jobs"
+	"github.com/Devashish08/frigga-assigment/backend/mailer"
 	"github.com/Devashish08/frigga-assigment/backend/middleware"
 	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,10 +21,7 @@ import (
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Allow multiple origins for development and production
-		allowedOrigins := []string{
-			"http://localhost:3000",
-			"https://frigga-assignment.vercel.app", // Update with your actual Vercel URL
-		}
+		allowedOrigins := config.GetAllowedOrigins()
 
 		origin := c.Request.Header.Get("Origin")
 		for _, allowedOrigin := range allowedOrigins {
@@ -31,8 +32,9 @@ func CORSMiddleware() gin.HandlerFunc {
 		}
 
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-Match, X-Share-Password, X-Workspace-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, X-Workspace-ID")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -49,17 +51,116 @@ func init() {
 }
 
 func main() {
-	err := config.DB.AutoMigrate(&models.User{}, &models.Document{}, &models.Permission{}, &models.Version{})
+	// Accounts from before email verification existed stay verified
+	hadEmailVerification := config.DB.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+
+	// Refresh tokens from before sessions existed have to be moved first
+	if err := auth.MigrateRefreshTokenFamilies(); err != nil {
+		panic("Failed to migrate refresh tokens: " + err.Error())
+	}
+
+	err := config.DB.AutoMigrate(
+		&models.User{},
+		&models.Document{},
+		&models.Permission{},
+		&models.Version{},
+		&models.Space{},
+		&models.SpacePermission{},
+		&models.Group{},
+		&models.GroupMember{},
+		&models.GroupPermission{},
+		&models.PermissionEvent{},
+		&models.AccessRequest{},
+		&models.Notification{},
+		&models.ShareLink{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.UserToken{},
+		&models.RecoveryCode{},
+		&models.UserIdentity{},
+		&models.PersonalAccessToken{},
+		&models.LoginAttempt{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.WorkspaceInvitation{},
+	)
 	if err != nil {
 		panic("Failed to migrate database")
 	}
+	if !hadEmailVerification {
+		if err := auth.VerifyExistingEmails(); err != nil {
+			panic("Failed to migrate email verification: " + err.Error())
+		}
+	}
+	if err := tenancy.Register(config.DB); err != nil {
+		panic("Failed to register workspace scoping: " + err.Error())
+	}
+	if err := tenancy.MigrateDefaultWorkspace(config.DB); err != nil {
+		panic("Failed to migrate to workspaces: " + err.Error())
+	}
+
+	mailer.Configure()
+	auth.ConfigureThrottle()
+	if err := auth.PromoteConfiguredAdmins(); err != nil {
+		panic("Failed to promote admins: " + err.Error())
+	}
+	if err := auth.LoadKeys(); err != nil {
+		panic("Failed to load signing keys: " + err.Error())
+	}
+
+	jobs.StartTrashPurger()
+	jobs.StartPermissionSweeper()
 
 	router := gin.Default()
+	// Login throttling counts failures per client IP
+	if err := router.SetTrustedProxies(config.GetTrustedProxies()); err != nil {
+		panic("Invalid TRUSTED_PROXIES: " + err.Error())
+	}
 	router.Use(CORSMiddleware())
+	router.GET("/.well-known/jwks.json", api.GetJWKS)
+
 	authRoutes := router.Group("/api/auth")
 	{
 		authRoutes.POST("/register", api.Register)
 		authRoutes.POST("/login", api.Login)
+		authRoutes.POST("/login/2fa", api.LoginTwoFactor)
+		authRoutes.GET("/oidc/login", api.OIDCLogin)
+		authRoutes.GET("/oidc/callback", api.OIDCCallback)
+		authRoutes.POST("/refresh", api.RefreshToken)
+		authRoutes.POST("/logout", api.Logout)
+		authRoutes.POST("/forgot-password", api.ForgotPassword)
+		authRoutes.POST("/reset-password", api.ResetPassword)
+		authRoutes.POST("/verify-email", api.VerifyEmail)
+		authRoutes.POST("/confirm-email", api.ConfirmEmailChange)
+
+		// Managing the signed-in account
+		accountRoutes := authRoutes.Group("/")
+		accountRoutes.Use(middleware.AuthMiddleware(), middleware.RequireScope(models.AdminScope))
+		{
+			accountRoutes.POST("/verify-email/resend", api.ResendVerificationEmail)
+			accountRoutes.POST("/logout-all", api.LogoutAll)
+			accountRoutes.GET("/sessions", api.GetSessions)
+			accountRoutes.DELETE("/sessions/:id", api.TerminateSession)
+			accountRoutes.POST("/2fa/enroll", api.EnrollTwoFactor)
+			accountRoutes.POST("/2fa/confirm", api.ConfirmTwoFactor)
+			accountRoutes.POST("/2fa/disable", api.DisableTwoFactor)
+			accountRoutes.POST("/2fa/recovery-codes", api.RegenerateRecoveryCodes)
+			accountRoutes.GET("/tokens", api.GetPersonalAccessTokens)
+			accountRoutes.POST("/tokens", api.CreatePersonalAccessToken)
+			accountRoutes.DELETE("/tokens/:id", api.RevokePersonalAccessToken)
+		}
+	}
+	adminRoutes := router.Group("/api/admin")
+	adminRoutes.Use(middleware.AuthMiddleware(), middleware.RequireScope(models.AdminScope), middleware.RequireAdmin())
+	{
+		adminRoutes.GET("/users", api.GetUsers)
+		adminRoutes.POST("/users/:id/deactivate", api.DeactivateUser)
+		adminRoutes.POST("/users/:id/reactivate", api.ReactivateUser)
+		adminRoutes.PATCH("/users/:id/role", api.UpdateUserRole)
+		adminRoutes.POST("/users/:id/force-password-reset", api.ForcePasswordReset)
+		adminRoutes.POST("/documents/:id/owner", api.ReassignDocument)
+		adminRoutes.GET("/stats", api.GetStats)
+		adminRoutes.POST("/unlock", api.UnlockLogin)
 	}
 	apiRoutes := router.Group("/api")
 	{
@@ -70,21 +171,77 @@ func main() {
 			})
 		})
 
+		// Read-only access for people without an account
+		apiRoutes.GET("/public/documents/:id", api.GetPublicDocument)
+		apiRoutes.GET("/public/share/:token", api.GetSharedDocument)
+
+		// Browsers cannot send the Authorization header when opening a websocket
+		apiRoutes.GET("/documents/:id/collab", middleware.WebSocketAuthMiddleware(), middleware.WorkspaceMiddleware(), api.CollaborateOnDocument)
+		apiRoutes.GET("/documents/:id/presence/ws", middleware.WebSocketAuthMiddleware(), middleware.WorkspaceMiddleware(), api.WatchDocumentPresence)
+
+		// Personal access tokens need the admin scope to change who has access
+		adminScope := middleware.RequireScope(models.AdminScope)
+
+		// The account and its workspaces, whichever workspace is active
+		accountRoutes := apiRoutes.Group("/")
+		accountRoutes.Use(middleware.AuthMiddleware())
+		{
+			accountRoutes.GET("/profile", api.GetProfile)
+			accountRoutes.PATCH("/profile", adminScope, api.UpdateProfile)
+			accountRoutes.DELETE("/profile", adminScope, api.DeleteProfile)
+			accountRoutes.POST("/profile/email", adminScope, api.ChangeEmail)
+			accountRoutes.POST("/profile/password", adminScope, api.ChangePassword)
+
+			accountRoutes.GET("/workspaces", api.GetWorkspaces)
+			accountRoutes.POST("/workspaces", adminScope, api.CreateWorkspace)
+			accountRoutes.GET("/workspaces/:id/members", api.GetWorkspaceMembers)
+			accountRoutes.POST("/workspaces/invitations/accept", adminScope, api.AcceptWorkspaceInvitation)
+			accountRoutes.GET("/workspaces/:id/invitations", api.GetWorkspaceInvitations)
+			accountRoutes.POST("/workspaces/:id/invitations", adminScope, api.InviteWorkspaceMember)
+			accountRoutes.DELETE("/workspaces/:id/invitations/:invitationId", adminScope, api.RevokeWorkspaceInvitation)
+			accountRoutes.PATCH("/workspaces/:id/members/:userId", adminScope, api.UpdateWorkspaceMember)
+			accountRoutes.DELETE("/workspaces/:id/members/:userId", adminScope, api.RemoveWorkspaceMember)
+		}
+
+		// Everything else happens in the workspace picked by WorkspaceMiddleware
 		protected := apiRoutes.Group("/")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(), middleware.WorkspaceMiddleware())
 		{
-			protected.GET("/profile", func(c *gin.Context) {
-				user, _ := c.Get("user")
-				c.JSON(http.StatusOK, gin.H{"message": "This is a protected route", "user": user})
-			})
 			protected.GET("/documents", api.GetDocuments)
 			protected.POST("/documents", api.CreateDocument)
 			protected.GET("/documents/search", api.SearchDocuments) // Add search route
 			protected.GET("/documents/:id", api.GetDocument)
 			protected.PUT("/documents/:id", api.UpdateDocument)
+			protected.DELETE("/documents/:id", api.DeleteDocument)
+
+			protected.GET("/trash", api.GetTrash)
+			protected.POST("/trash/:id/restore", api.RestoreDocument)
 
 			protected.GET("/users/search", api.SearchUsers)
 
+			protected.GET("/groups", api.GetGroups)
+			protected.POST("/groups", adminScope, api.CreateGroup)
+			protected.GET("/groups/:id", api.GetGroup)
+			protected.DELETE("/groups/:id", adminScope, api.DeleteGroup)
+			protected.POST("/groups/:id/members", adminScope, api.AddGroupMember)
+			protected.PATCH("/groups/:id/members/:userId", adminScope, api.UpdateGroupMember)
+			protected.DELETE("/groups/:id/members/:userId", adminScope, api.RemoveGroupMember)
+
+			protected.GET("/access-requests", api.GetAccessRequestInbox)
+			protected.POST("/access-requests/:id/approve", adminScope, api.ApproveAccessRequest)
+			protected.POST("/access-requests/:id/deny", adminScope, api.DenyAccessRequest)
+
+			protected.GET("/notifications", api.GetNotifications)
+			protected.POST("/notifications/:id/read", api.MarkNotificationRead)
+
+			protected.GET("/spaces", api.GetSpaces)
+			protected.POST("/spaces", api.CreateSpace)
+			protected.GET("/spaces/:id", api.GetSpace)
+			protected.GET("/spaces/:id/tree", api.GetSpaceTree)
+			protected.GET("/spaces/:id/permissions", api.GetSpacePermissions)
+			protected.POST("/spaces/:id/permissions", adminScope, api.AddSpacePermission)
+			protected.DELETE("/spaces/:id/permissions/:userId", adminScope, api.RemoveSpacePermission)
+
 			docPermissionRoutes := protected.Group("/documents/:id")
 			docPermissionRoutes.Use(func(c *gin.Context) {
 				id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -96,8 +253,28 @@ func main() {
 				c.Next()
 			})
 			{
-				docPermissionRoutes.POST("/permissions", api.AddPermission)
+				docPermissionRoutes.GET("/permissions", api.GetPermissionsForDocument)
+				docPermissionRoutes.POST("/permissions", adminScope, api.AddPermission)
+				docPermissionRoutes.PATCH("/permissions/:permissionId", adminScope, api.UpdatePermission)
+				docPermissionRoutes.DELETE("/permissions/:permissionId", adminScope, api.RevokePermission)
+				docPermissionRoutes.GET("/permissions/history", api.GetPermissionHistory)
+				docPermissionRoutes.POST("/group-permissions", adminScope, api.AddGroupPermission)
+				docPermissionRoutes.PATCH("/group-permissions/:permissionId", adminScope, api.UpdateGroupPermission)
+				docPermissionRoutes.DELETE("/group-permissions/:permissionId", adminScope, api.RevokeGroupPermission)
+				docPermissionRoutes.POST("/transfer", adminScope, api.TransferOwnership)
+				docPermissionRoutes.GET("/share-links", api.GetShareLinks)
+				docPermissionRoutes.POST("/share-links", adminScope, api.CreateShareLink)
+				docPermissionRoutes.DELETE("/share-links/:linkId", adminScope, api.RevokeShareLink)
+				docPermissionRoutes.GET("/access-requests", api.GetDocumentAccessRequests)
+				docPermissionRoutes.POST("/access-requests", api.RequestAccess)
 				docPermissionRoutes.GET("/versions", api.GetDocumentVersions)
+				docPermissionRoutes.POST("/versions/:versionId/restore", api.RestoreVersion)
+				docPermissionRoutes.GET("/diff", api.GetDocumentDiff)
+				docPermissionRoutes.GET("/presence", api.GetDocumentPresence)
+				docPermissionRoutes.POST("/move", api.MoveDocument)
+				docPermissionRoutes.GET("/breadcrumb", api.GetDocumentBreadcrumb)
+				docPermissionRoutes.GET("/children", api.GetDocumentChildren)
+				docPermissionRoutes.PUT("/restriction", adminScope, api.SetDocumentRestriction)
 			}
 		}
 	}
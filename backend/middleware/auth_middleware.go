@@ -1,15 +1,13 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
+	"github.com/Devashish08/frigga-assigment/backend/auth"
 	"github.com/Devashish08/frigga-assigment/backend/config"
 	"github.com/Devashish08/frigga-assigment/backend/models"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 func AuthMiddleware() gin.HandlerFunc {
@@ -26,38 +24,106 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(config.GetJWTSecret()), nil
-		})
+		authenticate(c, tokenString)
+	}
+}
+
+// WebSocketAuthMiddleware authenticates like AuthMiddleware but also accepts
+// the token in the "token" query parameter, since browsers cannot set headers
+// on a websocket handshake
+func WebSocketAuthMiddleware() gin.HandlerFunc {
+	headerAuth := AuthMiddleware()
+	return func(c *gin.Context) {
+		tokenString := c.Query("token")
+		if tokenString == "" {
+			headerAuth(c)
+			return
+		}
+		authenticate(c, tokenString)
+	}
+}
 
-		if err != nil || !token.Valid {
+// authenticate validates the access token or personal access token and
+// stores its user in the context
+func authenticate(c *gin.Context, tokenString string) {
+	var userID uint
+	scope := models.AdminScope
+
+	if auth.IsPersonalAccessToken(tokenString) {
+		pat, err := auth.AuthenticatePersonalAccessToken(tokenString)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		userID, scope = pat.UserID, pat.Scope
+	} else {
+		claims, err := auth.ParseAccessToken(tokenString)
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if float64(time.Now().Unix()) > claims["exp"].(float64) {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token is expired"})
-				return
-			}
+		if !auth.TouchSession(claims.SessionID) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been terminated"})
+			return
+		}
+		userID = claims.UserID
+		c.Set("session_id", claims.SessionID)
+	}
 
-			var user models.User
-			userID := uint(claims["sub"].(float64))
-			config.DB.First(&user, userID)
+	var user models.User
+	config.DB.First(&user, userID)
 
-			if user.ID == 0 {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-				return
-			}
+	if user.ID == 0 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	if !user.IsActive() {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This account has been deactivated"})
+		return
+	}
+	// Nothing issued before an admin forced a password reset keeps working
+	if user.MustResetPassword {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You must reset your password before continuing"})
+		return
+	}
+
+	// Read-only tokens cannot change anything, whatever the route
+	if !scope.Includes(models.WriteScope) && !isSafeMethod(c.Request.Method) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This token only allows reading"})
+		return
+	}
 
-			c.Set("user", user)
-			c.Next()
-		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+	c.Set("user", user)
+	c.Set("token_scope", scope)
+	c.Next()
+}
+
+// RequireScope stops personal access tokens without the given scope from
+// using a route. Logged-in users are not limited.
+func RequireScope(scope models.TokenScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenScope, _ := c.Get("token_scope")
+		if current, ok := tokenScope.(models.TokenScope); !ok || !current.Includes(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This token needs the " + string(scope) + " scope"})
+			return
 		}
+		c.Next()
+	}
+}
 
+// RequireAdmin stops users without the admin role from using a route
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userCtx, _ := c.Get("user")
+		if user, ok := userCtx.(models.User); !ok || !user.IsAdmin() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			return
+		}
+		c.Next()
 	}
 }
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
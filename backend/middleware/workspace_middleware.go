@@ -0,0 +1,55 @@
+// backend/middleware/workspace_middleware.go
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceMiddleware picks the workspace a request works in and limits every
+// query made for the request to it. The workspace comes from the
+// X-Workspace-ID header, or the "workspace" query parameter for websockets,
+// and defaults to the first workspace the user joined. It must run after
+// AuthMiddleware.
+func WorkspaceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userCtx, _ := c.Get("user")
+		user := userCtx.(models.User)
+
+		requested := c.GetHeader("X-Workspace-ID")
+		if requested == "" {
+			requested = c.Query("workspace")
+		}
+
+		query := config.DB.WithContext(tenancy.AllWorkspaces(c.Request.Context())).Where("user_id = ?", user.ID)
+		if requested != "" {
+			workspaceID, err := strconv.ParseUint(requested, 10, 64)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+				return
+			}
+			query = query.Where("workspace_id = ?", workspaceID)
+		}
+
+		var membership models.WorkspaceMember
+		if err := query.Order("created_at, id").First(&membership).Error; err != nil {
+			if requested != "" {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You are not a member of this workspace"})
+			} else {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You are not a member of any workspace"})
+			}
+			return
+		}
+
+		c.Set("workspace_id", membership.WorkspaceID)
+		c.Set("workspace_role", membership.Role)
+		c.Header("X-Workspace-ID", strconv.FormatUint(uint64(membership.WorkspaceID), 10))
+		c.Request = c.Request.WithContext(tenancy.WithWorkspace(c.Request.Context(), membership.WorkspaceID))
+		c.Next()
+	}
+}
@@ -0,0 +1,99 @@
+// backend/middleware/workspace_middleware_test.go
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/Devashish08/frigga-assigment/backend/config"
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/Devashish08/frigga-assigment/backend/tenancy"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestWorkspaceMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := tenancy.Register(db); err != nil {
+		t.Fatalf("register tenancy: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Workspace{}, &models.WorkspaceMember{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() { config.DB = previous })
+
+	all := db.WithContext(tenancy.AllWorkspaces(context.Background()))
+	user := models.User{Name: "Ada", Email: "ada@example.com", Password: "x"}
+	other := models.User{Name: "Bob", Email: "bob@example.com", Password: "x"}
+	all.Create(&user)
+	all.Create(&other)
+	first, _ := tenancy.CreateWorkspace(all, "First", user)
+	second, _ := tenancy.CreateWorkspace(all, "Second", user)
+	foreign, _ := tenancy.CreateWorkspace(all, "Foreign", other)
+
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Set("user", user)
+	}, WorkspaceMiddleware(), func(c *gin.Context) {
+		// Handlers only see the chosen workspace's members
+		var members []models.WorkspaceMember
+		if err := config.DB.WithContext(c.Request.Context()).Find(&members).Error; err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		for _, member := range members {
+			if member.WorkspaceID != c.GetUint("workspace_id") {
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name          string
+		header        string
+		wantStatus    int
+		wantWorkspace uint
+	}{
+		{"defaults to the first workspace joined", "", http.StatusNoContent, first.ID},
+		{"chosen workspace", strconv.Itoa(int(second.ID)), http.StatusNoContent, second.ID},
+		{"workspace of someone else", strconv.Itoa(int(foreign.ID)), http.StatusForbidden, 0},
+		{"unknown workspace", "999", http.StatusForbidden, 0},
+		{"invalid workspace ID", "abc", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				request.Header.Set("X-Workspace-ID", tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantWorkspace != 0 {
+				if got := recorder.Header().Get("X-Workspace-ID"); got != strconv.Itoa(int(tt.wantWorkspace)) {
+					t.Errorf("workspace = %s, want %d", got, tt.wantWorkspace)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// backend/models/access_request.go
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AccessRequestStatus is where an access request is in its review
+type AccessRequestStatus string
+
+const (
+	AccessRequestPending  AccessRequestStatus = "PENDING"
+	AccessRequestApproved AccessRequestStatus = "APPROVED"
+	AccessRequestDenied   AccessRequestStatus = "DENIED"
+)
+
+// AccessRequest is a user asking the people who manage a document for access to it
+type AccessRequest struct {
+	gorm.Model
+	DocumentID  uint                `gorm:"not null;index" json:"documentId"`
+	Document    *Document           `json:"document,omitempty"`
+	RequesterID uint                `gorm:"not null;index" json:"requesterId"`
+	Requester   User                `gorm:"foreignKey:RequesterID" json:"requester"`
+	Level       PermissionLevel     `gorm:"type:varchar(10);not null" json:"level"`
+	Message     string              `gorm:"type:text" json:"message"`
+	Status      AccessRequestStatus `gorm:"type:varchar(10);not null;default:'PENDING';index" json:"status"`
+
+	DecidedByID *uint      `json:"decidedById"`
+	DecidedBy   *User      `gorm:"foreignKey:DecidedByID" json:"decidedBy,omitempty"`
+	DecidedAt   *time.Time `json:"decidedAt"`
+}
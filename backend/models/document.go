@@ -10,4 +10,23 @@ type Document struct {
 	IsPublic bool   `gorm:"default:false;not null" json:"isPublic"` // <-- ADD THIS LINE
 	AuthorID uint   `gorm:"not null" json:"authorId"`
 	Author   User   `gorm:"foreignKey:AuthorID" json:"author"`
+
+	// WorkspaceID is the workspace that owns the document
+	WorkspaceID uint `gorm:"index" json:"workspaceId"`
+
+	// Pages live in a space and can be nested under another page of the same space
+	SpaceID  *uint `gorm:"index" json:"spaceId"`
+	ParentID *uint `gorm:"index" json:"parentId"`
+	Position int   `gorm:"not null;default:0" json:"position"`
+
+	// Restricted pages don't inherit permissions from their parent page or space
+	Restricted bool `gorm:"default:false;not null" json:"restricted"`
+
+	// Revision is bumped on every content change and used for optimistic locking
+	Revision uint `gorm:"not null;default:1" json:"revision"`
+
+	// DeletedByID records who moved the document to the trash
+	DeletedByID *uint `json:"deletedById,omitempty"`
 }
+
+func (Document) workspaceScoped() {}
@@ -0,0 +1,29 @@
+// backend/models/group.go
+package models
+
+import "gorm.io/gorm"
+
+// Group is a team of users that documents can be shared with as a whole
+type Group struct {
+	gorm.Model
+	Name        string        `gorm:"size:255;not null;uniqueIndex:idx_workspace_group_name" json:"name"`
+	Description string        `gorm:"type:text" json:"description"`
+	CreatedByID uint          `gorm:"not null" json:"createdById"`
+	Members     []GroupMember `json:"members,omitempty"`
+
+	// WorkspaceID is the workspace that owns the group. Names only need to be
+	// unique within a workspace.
+	WorkspaceID uint `gorm:"uniqueIndex:idx_workspace_group_name" json:"workspaceId"`
+}
+
+func (Group) workspaceScoped() {}
+
+// GroupMember is a user's membership of a group. Group admins manage the
+// group's members.
+type GroupMember struct {
+	gorm.Model
+	GroupID uint `gorm:"not null;uniqueIndex:idx_group_user" json:"groupId"`
+	UserID  uint `gorm:"not null;uniqueIndex:idx_group_user" json:"userId"`
+	User    User `gorm:"foreignKey:UserID" json:"user"`
+	IsAdmin bool `gorm:"default:false;not null" json:"isAdmin"`
+}
@@ -0,0 +1,18 @@
+// backend/models/login_attempt.go
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginAttempt counts recent failed logins for an account or an IP address,
+// for the database-backed login throttle
+type LoginAttempt struct {
+	gorm.Model
+	Key           string    `gorm:"size:320;not null;uniqueIndex"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   *time.Time
+}
@@ -0,0 +1,27 @@
+// backend/models/notification.go
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationType says what a notification is about
+type NotificationType string
+
+const (
+	NotificationAccessRequested NotificationType = "ACCESS_REQUESTED"
+	NotificationAccessApproved  NotificationType = "ACCESS_APPROVED"
+	NotificationAccessDenied    NotificationType = "ACCESS_DENIED"
+)
+
+// Notification is a message shown to a user in the app
+type Notification struct {
+	gorm.Model
+	UserID     uint             `gorm:"not null;index" json:"userId"`
+	Type       NotificationType `gorm:"type:varchar(32);not null" json:"type"`
+	Message    string           `gorm:"type:text;not null" json:"message"`
+	DocumentID *uint            `json:"documentId"`
+	ReadAt     *time.Time       `json:"readAt"`
+}
@@ -1,22 +1,115 @@
 // backend/models/permission.go
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type PermissionLevel string
 
 const (
-	ViewPermission PermissionLevel = "VIEW"
-	EditPermission PermissionLevel = "EDIT"
+	ViewPermission    PermissionLevel = "VIEW"
+	CommentPermission PermissionLevel = "COMMENT" // reserved for comments; until they exist it gives the same access as VIEW
+	EditPermission    PermissionLevel = "EDIT"
+	AdminPermission   PermissionLevel = "ADMIN" // can also share and change settings
+
+	// NoPermission is granted on a page to cut a user off from access they
+	// would otherwise inherit from a parent page or space
+	NoPermission PermissionLevel = "NONE"
 )
 
+// Rank orders permission levels from least to most access
+func (l PermissionLevel) Rank() int {
+	switch l {
+	case ViewPermission:
+		return 1
+	case CommentPermission:
+		return 2
+	case EditPermission:
+		return 3
+	case AdminPermission:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Includes reports whether the level grants at least the access of other
+func (l PermissionLevel) Includes(other PermissionLevel) bool {
+	return l.Rank() >= other.Rank()
+}
+
+// IsGrantable reports whether the level can be granted to a user or group
+func (l PermissionLevel) IsGrantable() bool {
+	return l.Rank() > 0
+}
+
 type Permission struct {
 	gorm.Model
 	UserID     uint            `gorm:"not null" json:"userId"`
+	User       User            `gorm:"foreignKey:UserID" json:"user"`
 	DocumentID uint            `gorm:"not null" json:"documentId"`
 	Level      PermissionLevel `gorm:"type:varchar(10);not null" json:"level"`
 
+	// GrantedByID is the user who shared the document, if known
+	GrantedByID *uint `json:"grantedById"`
+	GrantedBy   *User `gorm:"foreignKey:GrantedByID" json:"grantedBy,omitempty"`
+
+	// ExpiresAt ends the grant at a point in time, e.g. for contractors and auditors
+	ExpiresAt *time.Time `gorm:"index" json:"expiresAt"`
+
 	// Add a unique constraint to prevent duplicate permissions
 	// A user can only have one permission level per document
 	_ struct{} `gorm:"uniqueIndex:idx_user_document,fields:user_id,document_id"`
 }
+
+// SpacePermission grants a user access to every page of a space, unless a
+// page is restricted or overrides it with a permission of its own
+type SpacePermission struct {
+	gorm.Model
+	UserID  uint            `gorm:"not null;uniqueIndex:idx_user_space" json:"userId"`
+	User    User            `gorm:"foreignKey:UserID" json:"user"`
+	SpaceID uint            `gorm:"not null;uniqueIndex:idx_user_space" json:"spaceId"`
+	Level   PermissionLevel `gorm:"type:varchar(10);not null" json:"level"`
+}
+
+// GroupPermission grants every member of a group access to a document
+type GroupPermission struct {
+	gorm.Model
+	GroupID    uint            `gorm:"not null;uniqueIndex:idx_group_document" json:"groupId"`
+	Group      Group           `gorm:"foreignKey:GroupID" json:"group"`
+	DocumentID uint            `gorm:"not null;uniqueIndex:idx_group_document" json:"documentId"`
+	Level      PermissionLevel `gorm:"type:varchar(10);not null" json:"level"`
+
+	GrantedByID *uint `json:"grantedById"`
+	GrantedBy   *User `gorm:"foreignKey:GrantedByID" json:"grantedBy,omitempty"`
+}
+
+// PermissionAction is what happened to a permission in its history
+type PermissionAction string
+
+const (
+	PermissionGranted PermissionAction = "GRANTED"
+	PermissionUpdated PermissionAction = "UPDATED"
+	PermissionRevoked PermissionAction = "REVOKED"
+	PermissionExpired PermissionAction = "EXPIRED"
+)
+
+// PermissionEvent records a change to who can access a document
+type PermissionEvent struct {
+	gorm.Model
+	DocumentID uint             `gorm:"not null;index" json:"documentId"`
+	UserID     *uint            `json:"userId,omitempty"`
+	User       *User            `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	GroupID    *uint            `json:"groupId,omitempty"`
+	Group      *Group           `gorm:"foreignKey:GroupID" json:"group,omitempty"`
+	Action     PermissionAction `gorm:"type:varchar(10);not null" json:"action"`
+	Level      PermissionLevel  `gorm:"type:varchar(10)" json:"level"`
+	ExpiresAt  *time.Time       `json:"expiresAt,omitempty"`
+
+	// ActorID is who made the change; it is empty for changes made by the system
+	ActorID *uint `json:"actorId"`
+	Actor   *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
@@ -0,0 +1,52 @@
+// backend/models/personal_access_token.go
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenScope limits what a personal access token can do
+type TokenScope string
+
+const (
+	// ReadScope only allows reading
+	ReadScope TokenScope = "read"
+	// WriteScope also allows creating and changing documents
+	WriteScope TokenScope = "write"
+	// AdminScope also allows managing access and the account itself
+	AdminScope TokenScope = "admin"
+)
+
+// Rank orders scopes from least to most powerful
+func (s TokenScope) Rank() int {
+	switch s {
+	case ReadScope:
+		return 1
+	case WriteScope:
+		return 2
+	case AdminScope:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Includes reports whether a token with this scope can do what other allows
+func (s TokenScope) Includes(other TokenScope) bool {
+	return s.Rank() >= other.Rank()
+}
+
+// PersonalAccessToken lets scripts call the API as a user without their
+// password. Only a hash of the token is stored; deleting it revokes it.
+type PersonalAccessToken struct {
+	gorm.Model
+	UserID     uint       `gorm:"not null;index" json:"userId"`
+	Name       string     `gorm:"size:255;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"` // the start of the token, to tell tokens apart
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scope      TokenScope `gorm:"type:varchar(10);not null" json:"scope"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
@@ -0,0 +1,18 @@
+// backend/models/refresh_token.go
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken can be exchanged once for a new access token and a new
+// refresh token in the same session. Only a hash of the token is stored.
+type RefreshToken struct {
+	gorm.Model
+	SessionID uint       `gorm:"not null;index"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // set once the token has been exchanged
+}
@@ -0,0 +1,19 @@
+// backend/models/session.go
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session is one login of a user on a device. Its refresh tokens and the
+// access tokens issued with them stop working once it is revoked.
+type Session struct {
+	gorm.Model
+	UserID     uint       `gorm:"not null;index" json:"userId"`
+	UserAgent  string     `gorm:"size:512" json:"userAgent"`
+	IPAddress  string     `gorm:"size:64" json:"ipAddress"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	RevokedAt  *time.Time `gorm:"index" json:"-"`
+}
@@ -0,0 +1,20 @@
+// backend/models/share_link.go
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShareLink lets anyone holding its token read a document without an
+// account. Only a hash of the token is stored; deleting the link revokes it.
+type ShareLink struct {
+	gorm.Model
+	DocumentID   uint       `gorm:"not null;index" json:"documentId"`
+	TokenHash    string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	PasswordHash string     `gorm:"size:255" json:"-"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+	CreatedByID  uint       `gorm:"not null" json:"createdById"`
+	CreatedBy    User       `gorm:"foreignKey:CreatedByID" json:"createdBy"`
+}
@@ -0,0 +1,18 @@
+// backend/models/space.go
+package models
+
+import "gorm.io/gorm"
+
+// Space groups a tree of pages, like a team's section of the knowledge base
+type Space struct {
+	gorm.Model
+	Name        string `gorm:"size:255;not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	OwnerID     uint   `gorm:"not null" json:"ownerId"`
+	Owner       User   `gorm:"foreignKey:OwnerID" json:"owner"`
+
+	// WorkspaceID is the workspace that owns the space
+	WorkspaceID uint `gorm:"index" json:"workspaceId"`
+}
+
+func (Space) workspaceScoped() {}
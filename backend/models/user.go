@@ -1,11 +1,96 @@
 // backend/models/user.go
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
-	Name     string `gorm:"size:255;not null" json:"name"`
-	Email    string `gorm:"size:255;not null;unique" json:"email"`
-	Password string `gorm:"size:255;not null" json:"-"`
+	Name            string     `gorm:"size:255;not null" json:"name"`
+	Email           string     `gorm:"size:255;not null;unique" json:"email"`
+	Password        string     `gorm:"size:255;not null" json:"-"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+
+	// PendingEmail is the address the user asked to change to, which takes
+	// effect once they open the link sent to it
+	PendingEmail string `gorm:"size:255" json:"pendingEmail,omitempty"`
+
+	AvatarURL string `gorm:"size:2048" json:"avatarUrl"`
+	Timezone  string `gorm:"size:64" json:"timezone"` // IANA name, like Europe/Paris
+	Locale    string `gorm:"size:35" json:"locale"`   // BCP 47 tag, like en-GB
+
+	Role UserRole `gorm:"type:varchar(10);not null;default:MEMBER" json:"role"`
+
+	// DeactivatedAt is set when an admin turns the account off; deactivated
+	// users cannot log in or use any token
+	DeactivatedAt *time.Time `json:"deactivatedAt"`
+	// MustResetPassword is set when an admin forces a password reset, and
+	// blocks password logins until the user picks a new one
+	MustResetPassword bool `gorm:"default:false;not null" json:"mustResetPassword"`
+
+	// TOTPSecret is set when the user starts enrolling in two-factor
+	// authentication, which only takes effect once TOTPEnabled is set
+	TOTPSecret   string `gorm:"size:64" json:"-"`
+	TOTPEnabled  bool   `gorm:"default:false;not null" json:"totpEnabled"`
+	TOTPLastStep int64  `gorm:"default:0;not null" json:"-"` // stops a code from being used twice
+}
+
+// UserRole is what a user may do across the whole system
+type UserRole string
+
+const (
+	MemberRole UserRole = "MEMBER"
+	AdminRole  UserRole = "ADMIN"
+)
+
+// IsValid reports whether the role is one of the known roles
+func (r UserRole) IsValid() bool {
+	return r == MemberRole || r == AdminRole
+}
+
+// IsAdmin reports whether the user can use the admin console
+func (u User) IsAdmin() bool {
+	return u.Role == AdminRole
+}
+
+// IsActive reports whether the user's account has not been deactivated
+func (u User) IsActive() bool {
+	return u.DeactivatedAt == nil
+}
+
+// IsEmailVerified reports whether the user has confirmed they own their email address
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// TokenPurpose is what a UserToken can be used for
+type TokenPurpose string
+
+const (
+	PasswordResetToken     TokenPurpose = "PASSWORD_RESET"
+	EmailVerificationToken TokenPurpose = "EMAIL_VERIFICATION"
+	EmailChangeToken       TokenPurpose = "EMAIL_CHANGE"
+)
+
+// UserToken is a single-use, expiring token emailed to a user to prove they
+// own their address. Only a hash of the token is stored.
+type UserToken struct {
+	gorm.Model
+	UserID    uint         `gorm:"not null;index"`
+	Purpose   TokenPurpose `gorm:"type:varchar(32);not null"`
+	TokenHash string       `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time    `gorm:"not null"`
+	UsedAt    *time.Time
+}
+
+// RecoveryCode is a one-time code that can stand in for a TOTP code when the
+// user has lost their authenticator. Only a hash of the code is stored.
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	CodeHash string `gorm:"size:64;not null"`
+	UsedAt   *time.Time
 }
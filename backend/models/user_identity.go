@@ -0,0 +1,14 @@
+// backend/models/user_identity.go
+package models
+
+import "gorm.io/gorm"
+
+// UserIdentity links a user to an account at an external identity provider,
+// identified by the provider's issuer and its subject for the user
+type UserIdentity struct {
+	gorm.Model
+	UserID  uint   `gorm:"not null;index" json:"userId"`
+	Issuer  string `gorm:"size:255;not null;uniqueIndex:idx_issuer_subject" json:"issuer"`
+	Subject string `gorm:"size:255;not null;uniqueIndex:idx_issuer_subject" json:"subject"`
+	Email   string `gorm:"size:255" json:"email"`
+}
@@ -0,0 +1,65 @@
+// backend/models/workspace.go
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Workspace is a separate knowledge base on a shared deployment, such as one
+// for a company and one for a partner. It owns its documents, spaces, groups
+// and memberships; users can belong to several workspaces.
+type Workspace struct {
+	gorm.Model
+	Name        string `gorm:"size:255;not null" json:"name"`
+	CreatedByID uint   `json:"createdById"`
+}
+
+// WorkspaceScoped is implemented by models that belong to one workspace.
+// Queries on them only ever see the active workspace; see package tenancy.
+type WorkspaceScoped interface {
+	workspaceScoped()
+}
+
+// WorkspaceRole is what a member may do in a workspace
+type WorkspaceRole string
+
+const (
+	WorkspaceMemberRole WorkspaceRole = "MEMBER"
+	WorkspaceAdminRole  WorkspaceRole = "ADMIN" // can also manage the workspace's members
+)
+
+// IsValid reports whether the role is one of the known workspace roles
+func (r WorkspaceRole) IsValid() bool {
+	return r == WorkspaceMemberRole || r == WorkspaceAdminRole
+}
+
+// WorkspaceMember is a user's membership of a workspace
+type WorkspaceMember struct {
+	gorm.Model
+	WorkspaceID uint          `gorm:"not null;uniqueIndex:idx_workspace_user" json:"workspaceId"`
+	UserID      uint          `gorm:"not null;uniqueIndex:idx_workspace_user;index" json:"userId"`
+	User        User          `gorm:"foreignKey:UserID" json:"user"`
+	Role        WorkspaceRole `gorm:"type:varchar(10);not null;default:MEMBER" json:"role"`
+}
+
+func (WorkspaceMember) workspaceScoped() {}
+
+// WorkspaceInvitation asks someone to join a workspace. It is sent to an
+// email address rather than a user, and only takes effect when the owner of
+// that address accepts it, so nobody is added to a workspace without their
+// consent. Only a hash of the token is stored.
+type WorkspaceInvitation struct {
+	gorm.Model
+	WorkspaceID uint          `gorm:"not null;index" json:"workspaceId"`
+	Email       string        `gorm:"size:255;not null" json:"email"`
+	Role        WorkspaceRole `gorm:"type:varchar(10);not null;default:MEMBER" json:"role"`
+	TokenHash   string        `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	InvitedByID uint          `json:"invitedById"`
+	InvitedBy   User          `gorm:"foreignKey:InvitedByID" json:"invitedBy"`
+	ExpiresAt   time.Time     `gorm:"not null" json:"expiresAt"`
+	AcceptedAt  *time.Time    `json:"acceptedAt"`
+}
+
+func (WorkspaceInvitation) workspaceScoped() {}
@@ -0,0 +1,75 @@
+// backend/presence/conn.go
+package presence
+
+import (
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pingPeriod     = 30 * time.Second
+	maxMessageSize = 4096
+)
+
+// clientMessage is what viewers send: a "heartbeat" to stay present, or a
+// "cursor" with their current selection
+type clientMessage struct {
+	Type   string  `json:"type"`
+	Cursor *Cursor `json:"cursor"`
+}
+
+// Serve marks the user as viewing the document for as long as the connection
+// stays open and keeps sending heartbeats, and streams presence events to it
+func (t *Tracker) Serve(conn *websocket.Conn, documentID uint, user models.User) {
+	m := t.join(documentID, user)
+	defer t.leave(m)
+
+	go writeEvents(conn, m.events)
+
+	conn.SetReadLimit(maxMessageSize)
+	for {
+		var msg clientMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			conn.Close()
+			return
+		}
+
+		switch msg.Type {
+		case "cursor":
+			t.touch(m, msg.Cursor)
+		default:
+			t.touch(m, nil)
+		}
+	}
+}
+
+// writeEvents sends events until the viewer leaves, then closes the connection
+func writeEvents(conn *websocket.Conn, events <-chan Event) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case event, ok := <-events:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+// backend/presence/tracker.go
+package presence
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+)
+
+const (
+	// heartbeatTimeout is how long a viewer stays present without sending anything
+	heartbeatTimeout = 45 * time.Second
+	// sweepInterval is how often stale viewers are removed
+	sweepInterval = 15 * time.Second
+	eventBuffer   = 64
+)
+
+// Cursor is a viewer's selection in the document. Anchor and Head are equal
+// when nothing is selected.
+type Cursor struct {
+	Anchor int `json:"anchor"`
+	Head   int `json:"head"`
+}
+
+// Viewer is a user who has a document open in one browser tab or client
+type Viewer struct {
+	ConnectionID string    `json:"connectionId"`
+	UserID       uint      `json:"userId"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Cursor       *Cursor   `json:"cursor,omitempty"`
+	JoinedAt     time.Time `json:"joinedAt"`
+	LastSeenAt   time.Time `json:"lastSeenAt"`
+}
+
+// Event is sent to viewers when someone joins, leaves or moves their cursor.
+// A "snapshot" event lists everyone present and is sent right after joining.
+// Like Viewers, the snapshot and the join and leave events are per user: a
+// user opening a second tab does not join again, and only closing the last
+// one makes them leave.
+type Event struct {
+	Type    string   `json:"type"`
+	Viewer  *Viewer  `json:"viewer,omitempty"`
+	Viewers []Viewer `json:"viewers,omitempty"`
+}
+
+type member struct {
+	documentID uint
+	viewer     Viewer
+	events     chan Event
+}
+
+// Tracker knows who has which document open
+type Tracker struct {
+	mu     sync.Mutex
+	rooms  map[uint]map[string]*member
+	nextID uint64
+	sweep  sync.Once
+}
+
+// DefaultTracker is the tracker used by the API
+var DefaultTracker = NewTracker()
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{rooms: make(map[uint]map[string]*member)}
+}
+
+// Viewers lists the users that currently have the document open, one entry per
+// user with their most recently active connection
+func (t *Tracker) Viewers(documentID uint) []Viewer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return latestViewers(t.rooms[documentID])
+}
+
+// latestViewers lists the most recently active connection of each user in a
+// room, in the order they joined; t.mu must be held
+func latestViewers(room map[string]*member) []Viewer {
+	latest := make(map[uint]Viewer)
+	for _, m := range room {
+		if current, ok := latest[m.viewer.UserID]; !ok || m.viewer.LastSeenAt.After(current.LastSeenAt) {
+			latest[m.viewer.UserID] = m.viewer
+		}
+	}
+
+	viewers := make([]Viewer, 0, len(latest))
+	for _, viewer := range latest {
+		viewers = append(viewers, viewer)
+	}
+	sort.Slice(viewers, func(i, j int) bool { return viewers[i].JoinedAt.Before(viewers[j].JoinedAt) })
+	return viewers
+}
+
+// present reports whether the user has a connection in the room; t.mu must be held
+func present(room map[string]*member, userID uint) bool {
+	for _, m := range room {
+		if m.viewer.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tracker) join(documentID uint, user models.User) *member {
+	t.sweep.Do(func() { go t.sweepLoop() })
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.nextID++
+	now := time.Now()
+	m := &member{
+		documentID: documentID,
+		viewer: Viewer{
+			ConnectionID: strconv.FormatUint(t.nextID, 10),
+			UserID:       user.ID,
+			Name:         user.Name,
+			Email:        user.Email,
+			JoinedAt:     now,
+			LastSeenAt:   now,
+		},
+		events: make(chan Event, eventBuffer),
+	}
+
+	room, ok := t.rooms[documentID]
+	if !ok {
+		room = make(map[string]*member)
+		t.rooms[documentID] = room
+	}
+
+	joined := !present(room, user.ID)
+	room[m.viewer.ConnectionID] = m
+
+	m.push(Event{Type: "snapshot", Viewers: latestViewers(room)})
+	if joined {
+		viewer := m.viewer
+		t.broadcast(m, Event{Type: "join", Viewer: &viewer})
+	}
+	return m
+}
+
+// leave removes a viewer; it is safe to call more than once
+func (t *Tracker) leave(m *member) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.remove(m)
+}
+
+// remove must be called with t.mu held
+func (t *Tracker) remove(m *member) {
+	room := t.rooms[m.documentID]
+	if _, ok := room[m.viewer.ConnectionID]; !ok {
+		return
+	}
+
+	delete(room, m.viewer.ConnectionID)
+	close(m.events)
+	if len(room) == 0 {
+		delete(t.rooms, m.documentID)
+	}
+
+	// The user is still present in their other tabs
+	if !present(room, m.viewer.UserID) {
+		viewer := m.viewer
+		t.broadcast(m, Event{Type: "leave", Viewer: &viewer})
+	}
+}
+
+// touch records a heartbeat, and a cursor move when cursor is set
+func (t *Tracker) touch(m *member, cursor *Cursor) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.rooms[m.documentID][m.viewer.ConnectionID]; !ok {
+		return
+	}
+
+	m.viewer.LastSeenAt = time.Now()
+	if cursor != nil {
+		m.viewer.Cursor = cursor
+		viewer := m.viewer
+		t.broadcast(m, Event{Type: "cursor", Viewer: &viewer})
+	}
+}
+
+// broadcast must be called with t.mu held
+func (t *Tracker) broadcast(except *member, event Event) {
+	for _, m := range t.rooms[except.documentID] {
+		if m != except {
+			m.push(event)
+		}
+	}
+}
+
+// push queues an event, dropping it if the viewer is not keeping up
+func (m *member) push(event Event) {
+	select {
+	case m.events <- event:
+	default:
+	}
+}
+
+func (t *Tracker) sweepLoop() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cutoff := time.Now().Add(-heartbeatTimeout)
+
+		t.mu.Lock()
+		for _, room := range t.rooms {
+			for _, m := range room {
+				if m.viewer.LastSeenAt.Before(cutoff) {
+					t.remove(m)
+				}
+			}
+		}
+		t.mu.Unlock()
+	}
+}
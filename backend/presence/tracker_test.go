@@ -0,0 +1,53 @@
+// backend/presence/tracker_test.go
+package presence
+
+import (
+	"testing"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+)
+
+// events returns the events queued for a member so far
+func events(m *member) []Event {
+	var queued []Event
+	for {
+		select {
+		case event := <-m.events:
+			queued = append(queued, event)
+		default:
+			return queued
+		}
+	}
+}
+
+func TestTrackerCountsUsersNotTabs(t *testing.T) {
+	tracker := NewTracker()
+	ada, bob := models.User{Name: "Ada"}, models.User{Name: "Bob"}
+	ada.ID, bob.ID = 1, 2
+
+	observer := tracker.join(1, bob)
+	first := tracker.join(1, ada)
+	second := tracker.join(1, ada)
+
+	snapshot := events(second)
+	if len(snapshot) != 1 || len(snapshot[0].Viewers) != 2 {
+		t.Fatalf("second tab got %+v, want a snapshot of 2 viewers", snapshot)
+	}
+	if snapshot[0].Viewers[1].ConnectionID != second.viewer.ConnectionID {
+		t.Errorf("snapshot shows connection %s of Ada, want the latest", snapshot[0].Viewers[1].ConnectionID)
+	}
+	if got := len(tracker.Viewers(1)); got != 2 {
+		t.Errorf("Viewers lists %d, want 2", got)
+	}
+
+	tracker.leave(first)
+	tracker.leave(second)
+
+	var types []string
+	for _, event := range events(observer) {
+		types = append(types, event.Type)
+	}
+	if len(types) != 3 || types[0] != "snapshot" || types[1] != "join" || types[2] != "leave" {
+		t.Errorf("observer got %v, want snapshot, join and leave", types)
+	}
+}
@@ -0,0 +1,155 @@
+// backend/tenancy/tenancy.go
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrNoWorkspace is returned by queries on workspace data whose context
+	// names no workspace
+	ErrNoWorkspace = errors.New("query on workspace data without an active workspace")
+
+	// ErrWrongWorkspace is returned when creating a row for a workspace other
+	// than the active one
+	ErrWrongWorkspace = errors.New("row belongs to another workspace")
+)
+
+type contextKey int
+
+const (
+	workspaceKey contextKey = iota
+	allWorkspacesKey
+)
+
+// scopedKey marks a statement that already has its workspace condition, so
+// running it twice, as Count then Find do, does not add it again
+const scopedKey = "tenancy:scoped"
+
+// WithWorkspace returns a context whose queries are limited to a workspace
+func WithWorkspace(ctx context.Context, workspaceID uint) context.Context {
+	return context.WithValue(ctx, workspaceKey, workspaceID)
+}
+
+// AllWorkspaces returns a context whose queries see every workspace. It is
+// meant for background jobs, system admins and account-wide changes.
+func AllWorkspaces(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allWorkspacesKey, true)
+}
+
+// WorkspaceID returns the workspace a context is limited to
+func WorkspaceID(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(workspaceKey).(uint)
+	return id, ok && id != 0
+}
+
+func seesAllWorkspaces(ctx context.Context) bool {
+	all, _ := ctx.Value(allWorkspacesKey).(bool)
+	return all
+}
+
+// Register installs the callbacks that keep workspaces apart. Every query on
+// a model that implements models.WorkspaceScoped is limited to the workspace
+// carried by the query's context, and new rows are stamped with it. A query
+// without a workspace fails instead of seeing every workspace's data; the few
+// places that really work across workspaces must say so with AllWorkspaces.
+func Register(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("tenancy:scope", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenancy:scope", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenancy:scope", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenancy:scope", scope); err != nil {
+		return err
+	}
+	return callbacks.Create().Before("gorm:create").Register("tenancy:assign", assign)
+}
+
+func isScoped(db *gorm.DB) bool {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return false
+	}
+	_, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(models.WorkspaceScoped)
+	return ok
+}
+
+// scope adds the active workspace to the conditions of a statement
+func scope(db *gorm.DB) {
+	if !isScoped(db) {
+		return
+	}
+	if _, done := db.Statement.Settings.Load(scopedKey); done {
+		return
+	}
+
+	ctx := db.Statement.Context
+	if seesAllWorkspaces(ctx) {
+		return
+	}
+	workspaceID, ok := WorkspaceID(ctx)
+	if !ok {
+		db.AddError(ErrNoWorkspace)
+		return
+	}
+
+	db.Statement.Settings.Store(scopedKey, true)
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "workspace_id"}, Value: workspaceID},
+	}})
+}
+
+// assign stamps new rows with the active workspace. Across all workspaces the
+// caller has to set the workspace itself.
+func assign(db *gorm.DB) {
+	if !isScoped(db) {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("WorkspaceID")
+	if field == nil {
+		db.AddError(ErrNoWorkspace)
+		return
+	}
+
+	ctx := db.Statement.Context
+	workspaceID, ok := WorkspaceID(ctx)
+	if !ok && !seesAllWorkspaces(ctx) {
+		db.AddError(ErrNoWorkspace)
+		return
+	}
+
+	stamp := func(row reflect.Value) {
+		current, zero := field.ValueOf(ctx, row)
+		switch {
+		case zero && ok:
+			db.AddError(field.Set(ctx, row, workspaceID))
+		case zero:
+			db.AddError(ErrNoWorkspace)
+		case ok && current.(uint) != workspaceID:
+			db.AddError(ErrWrongWorkspace)
+		}
+	}
+
+	rows := db.Statement.ReflectValue
+	switch rows.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rows.Len(); i++ {
+			stamp(reflect.Indirect(rows.Index(i)))
+		}
+	case reflect.Struct:
+		stamp(rows)
+	default:
+		// Rows given as maps cannot be stamped
+		db.AddError(ErrNoWorkspace)
+	}
+}
@@ -0,0 +1,185 @@
+// backend/tenancy/tenancy_test.go
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB returns an empty SQLite database with the tenancy callbacks
+// installed, and a document in each of workspaces 1 and 2
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := Register(db); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Document{}, &models.Workspace{}, &models.WorkspaceMember{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	author := models.User{Name: "Ada", Email: "ada@example.com", Password: "x"}
+	if err := db.Create(&author).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	for _, workspaceID := range []uint{1, 2} {
+		err := inWorkspace(db, workspaceID).Create(&models.Document{Title: "Document", AuthorID: author.ID}).Error
+		if err != nil {
+			t.Fatalf("create document in workspace %d: %v", workspaceID, err)
+		}
+	}
+	return db
+}
+
+func inWorkspace(db *gorm.DB, workspaceID uint) *gorm.DB {
+	return db.WithContext(WithWorkspace(context.Background(), workspaceID))
+}
+
+func TestQueriesNeedAWorkspace(t *testing.T) {
+	db := openTestDB(t)
+
+	var documents []models.Document
+	if err := db.Find(&documents).Error; !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("Find without a workspace returned %v, want ErrNoWorkspace", err)
+	}
+	var count int64
+	if err := db.Model(&models.Document{}).Count(&count).Error; !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("Count without a workspace returned %v, want ErrNoWorkspace", err)
+	}
+	if err := db.Model(&models.Document{}).Where("1 = 1").Update("title", "x").Error; !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("Update without a workspace returned %v, want ErrNoWorkspace", err)
+	}
+
+	// Models outside workspaces are not affected
+	var users []models.User
+	if err := db.Find(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("Find users = %d, %v", len(users), err)
+	}
+}
+
+func TestQueriesSeeOneWorkspace(t *testing.T) {
+	db := openTestDB(t)
+
+	var documents []models.Document
+	if err := inWorkspace(db, 1).Find(&documents).Error; err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(documents) != 1 || documents[0].WorkspaceID != 1 {
+		t.Fatalf("workspace 1 sees %+v", documents)
+	}
+
+	var other models.Document
+	if err := inWorkspace(db, 1).First(&other, documents[0].ID+1).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("First of workspace 2's document from workspace 1 returned %v, want not found", err)
+	}
+
+	// Count then Find on the same statement must not add the condition twice
+	var count int64
+	query := inWorkspace(db, 2).Model(&models.Document{})
+	if err := query.Count(&count).Find(&documents).Error; err != nil || count != 1 || len(documents) != 1 {
+		t.Errorf("Count then Find = %d, %d, %v", count, len(documents), err)
+	}
+
+	result := inWorkspace(db, 1).Model(&models.Document{}).Where("1 = 1").Update("title", "Renamed")
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("Update affected %d rows, %v", result.RowsAffected, result.Error)
+	}
+	result = inWorkspace(db, 1).Where("1 = 1").Delete(&models.Document{})
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("Delete affected %d rows, %v", result.RowsAffected, result.Error)
+	}
+
+	var untouched models.Document
+	inWorkspace(db, 2).First(&untouched)
+	if untouched.Title != "Document" {
+		t.Errorf("workspace 2's document became %q", untouched.Title)
+	}
+}
+
+func TestSubqueriesSeeOneWorkspace(t *testing.T) {
+	db := openTestDB(t)
+
+	// The outer query sees every workspace, so only the subquery can limit it
+	var ids []uint
+	subquery := inWorkspace(db, 1).Model(&models.Document{}).Select("id")
+	err := db.WithContext(AllWorkspaces(context.Background())).Model(&models.Document{}).
+		Where("id IN (?)", subquery).Pluck("id", &ids).Error
+	if err != nil || len(ids) != 1 {
+		t.Errorf("subquery found %d documents, %v", len(ids), err)
+	}
+}
+
+func TestCreateStampsWorkspace(t *testing.T) {
+	db := openTestDB(t)
+
+	document := models.Document{Title: "New", AuthorID: 1}
+	if err := inWorkspace(db, 2).Create(&document).Error; err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if document.WorkspaceID != 2 {
+		t.Errorf("document created in workspace %d, want 2", document.WorkspaceID)
+	}
+
+	foreign := models.Document{Title: "Foreign", AuthorID: 1, WorkspaceID: 1}
+	if err := inWorkspace(db, 2).Create(&foreign).Error; !errors.Is(err, ErrWrongWorkspace) {
+		t.Errorf("Create for another workspace returned %v, want ErrWrongWorkspace", err)
+	}
+
+	if err := db.Create(&models.Document{Title: "Nowhere", AuthorID: 1}).Error; !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("Create without a workspace returned %v, want ErrNoWorkspace", err)
+	}
+
+	all := db.WithContext(AllWorkspaces(context.Background()))
+	if err := all.Create(&models.Document{Title: "Unassigned", AuthorID: 1}).Error; !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("Create across workspaces without a workspace ID returned %v, want ErrNoWorkspace", err)
+	}
+	if err := all.Create(&models.Document{Title: "Assigned", AuthorID: 1, WorkspaceID: 1}).Error; err != nil {
+		t.Errorf("Create across workspaces with a workspace ID: %v", err)
+	}
+}
+
+func TestAllWorkspaces(t *testing.T) {
+	db := openTestDB(t)
+
+	var count int64
+	if err := db.WithContext(AllWorkspaces(context.Background())).Model(&models.Document{}).Count(&count).Error; err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("all workspaces see %d documents, want 2", count)
+	}
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	db := openTestDB(t)
+
+	owner := models.User{Name: "Bob", Email: "bob@example.com", Password: "x"}
+	db.Create(&owner)
+	workspace, err := CreatePersonalWorkspace(db.WithContext(AllWorkspaces(context.Background())), owner)
+	if err != nil {
+		t.Fatalf("CreatePersonalWorkspace: %v", err)
+	}
+	if workspace.Name != "Bob's workspace" {
+		t.Errorf("workspace name = %q", workspace.Name)
+	}
+
+	var member models.WorkspaceMember
+	if err := inWorkspace(db, workspace.ID).Where("user_id = ?", owner.ID).First(&member).Error; err != nil {
+		t.Fatalf("owner is not a member: %v", err)
+	}
+	if member.Role != models.WorkspaceAdminRole {
+		t.Errorf("owner role = %s, want %s", member.Role, models.WorkspaceAdminRole)
+	}
+}
@@ -0,0 +1,92 @@
+// backend/tenancy/workspace.go
+package tenancy
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Devashish08/frigga-assigment/backend/models"
+	"gorm.io/gorm"
+)
+
+// CreateWorkspace makes a workspace with the given user as its admin
+func CreateWorkspace(tx *gorm.DB, name string, owner models.User) (models.Workspace, error) {
+	workspace := models.Workspace{Name: name, CreatedByID: owner.ID}
+	if err := tx.Create(&workspace).Error; err != nil {
+		return models.Workspace{}, err
+	}
+	err := tx.WithContext(WithWorkspace(tx.Statement.Context, workspace.ID)).Create(&models.WorkspaceMember{
+		UserID: owner.ID,
+		Role:   models.WorkspaceAdminRole,
+	}).Error
+	return workspace, err
+}
+
+// CreatePersonalWorkspace gives a new user a workspace of their own
+func CreatePersonalWorkspace(tx *gorm.DB, user models.User) (models.Workspace, error) {
+	return CreateWorkspace(tx, fmt.Sprintf("%s's workspace", user.Name), user)
+}
+
+// MigrateDefaultWorkspace moves data from before workspaces existed into a
+// workspace. The first time it runs on a database with users it creates a
+// "Default" workspace and makes everyone a member, system admins as its
+// admins, so nobody loses access to what they could see before.
+func MigrateDefaultWorkspace(db *gorm.DB) error {
+	db = db.WithContext(AllWorkspaces(context.Background()))
+
+	// Group names used to be unique across the whole deployment
+	if db.Migrator().HasConstraint(&models.Group{}, "uni_groups_name") {
+		if err := db.Migrator().DropConstraint(&models.Group{}, "uni_groups_name"); err != nil {
+			return err
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var workspace models.Workspace
+		result := tx.Order("id").Limit(1).Find(&workspace)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var users []models.User
+			if err := tx.Order("id").Find(&users).Error; err != nil {
+				return err
+			}
+			if len(users) == 0 {
+				return nil
+			}
+
+			workspace = models.Workspace{Name: "Default"}
+			if err := tx.Create(&workspace).Error; err != nil {
+				return err
+			}
+			members := make([]models.WorkspaceMember, 0, len(users))
+			for _, user := range users {
+				role := models.WorkspaceMemberRole
+				if user.IsAdmin() {
+					role = models.WorkspaceAdminRole
+				}
+				members = append(members, models.WorkspaceMember{WorkspaceID: workspace.ID, UserID: user.ID, Role: role})
+			}
+			// Without system admins the first user looks after the workspace
+			if !users[0].IsAdmin() {
+				members[0].Role = models.WorkspaceAdminRole
+			}
+			if err := tx.Create(&members).Error; err != nil {
+				return err
+			}
+			log.Printf("Moved %d user(s) into the default workspace", len(users))
+		}
+
+		for _, model := range []interface{}{&models.Document{}, &models.Space{}, &models.Group{}} {
+			err := tx.Unscoped().Model(model).
+				Where("workspace_id IS NULL OR workspace_id = 0").
+				Update("workspace_id", workspace.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+// frontend/src/app/auth/callback/page.tsx
+'use client';
+
+import { useEffect, useState } from 'react';
+import { useRouter } from 'next/navigation';
+import { TwoFactorForm } from '@/components/TwoFactorForm';
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/components/ui/card';
+import { storeSession } from '@/lib/api';
+
+// The backend redirects here after single sign-on with the tokens in the URL
+// fragment, which browsers never send to a server. Accounts with two-factor
+// authentication get a challenge instead, which is completed here.
+export default function AuthCallbackPage() {
+  const router = useRouter();
+  const [error, setError] = useState<string | null>(null);
+  const [challengeToken, setChallengeToken] = useState<string | null>(null);
+
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.hash.slice(1));
+    window.history.replaceState(null, '', window.location.pathname);
+
+    const challenge = params.get('challengeToken');
+    if (challenge) {
+      setChallengeToken(challenge);
+      return;
+    }
+
+    const token = params.get('token');
+    if (!token) {
+      setError('Single sign-on failed. Please try again.');
+      return;
+    }
+
+    storeSession({ token, refreshToken: params.get('refreshToken') ?? '' });
+    router.replace('/dashboard');
+  }, [router]);
+
+  if (challengeToken) {
+    return (
+      <div className="flex items-center justify-center min-h-screen bg-gray-100">
+        <Card className="w-[400px]">
+          <CardHeader>
+            <CardTitle>Two-factor authentication</CardTitle>
+            <CardDescription>Enter the code from your authenticator app.</CardDescription>
+          </CardHeader>
+          <CardContent>
+            <TwoFactorForm
+              challengeToken={challengeToken}
+              onRestart={() => router.replace('/login?error=invalid_state')}
+            />
+          </CardContent>
+        </Card>
+      </div>
+    );
+  }
+
+  return (
+    <div className="flex items-center justify-center min-h-screen bg-gray-100">
+      <p className="text-sm text-gray-600">{error ?? 'Signing you in...'}</p>
+    </div>
+  );
+}
@@ -0,0 +1,65 @@
+// frontend/src/app/confirm-email/page.tsx
+'use client';
+
+import Link from 'next/link';
+import { useEffect, useRef, useState } from 'react';
+import { Button } from '@/components/ui/button';
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/components/ui/card';
+
+// The link sent to a new email address opens this page with ?token=
+export default function ConfirmEmailPage() {
+  const [status, setStatus] = useState<'confirming' | 'confirmed' | 'failed'>('confirming');
+  const [message, setMessage] = useState('Confirming your new email address...');
+  // Tokens are single use, so the request must not be repeated when the
+  // effect runs twice in development
+  const requested = useRef(false);
+
+  useEffect(() => {
+    if (requested.current) {
+      return;
+    }
+    requested.current = true;
+
+    const confirmEmail = async () => {
+      const token = new URLSearchParams(window.location.search).get('token');
+      if (!token) {
+        setStatus('failed');
+        setMessage('This confirmation link is incomplete.');
+        return;
+      }
+
+      try {
+        const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL}/api/auth/confirm-email`, {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({ token }),
+        });
+        const data = await response.json();
+        setStatus(response.ok ? 'confirmed' : 'failed');
+        setMessage(response.ok ? data.message : data.error || 'Confirmation failed');
+      } catch {
+        setStatus('failed');
+        setMessage('Confirmation failed. Please try again.');
+      }
+    };
+    confirmEmail();
+  }, []);
+
+  return (
+    <div className="flex items-center justify-center min-h-screen bg-gray-100">
+      <Card className="w-[400px]">
+        <CardHeader>
+          <CardTitle>Confirm your new email</CardTitle>
+          <CardDescription>{message}</CardDescription>
+        </CardHeader>
+        {status !== 'confirming' && (
+          <CardContent>
+            <Button className="w-full" asChild>
+              <Link href="/dashboard">Continue</Link>
+            </Button>
+          </CardContent>
+        )}
+      </Card>
+    </div>
+  );
+}
@@ -10,6 +10,8 @@ import { Document } from '@/types';
 import { Card, CardDescription, CardFooter, CardHeader, CardTitle } from '@/components/ui/card';
 import { format } from 'date-fns';
 import Link from 'next/link';
+import { WorkspaceSwitcher } from '@/components/WorkspaceSwitcher';
+import { apiFetch, logout } from '@/lib/api';
 
 function DashboardPage() {
   const router = useRouter();
@@ -21,21 +23,12 @@ function DashboardPage() {
     const fetchDocuments = async () => {
       setIsLoading(true);
       setError(null);
-      const token = localStorage.getItem('authToken');
 
       try {
-        const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL}/api/documents`, {
-          headers: {
-            'Authorization': `Bearer ${token}`,
-          },
-        });
+        // An expired session is refreshed, or sends the user to the login page
+        const response = await apiFetch('/api/documents');
 
         if (!response.ok) {
-          if (response.status === 401) {
-            // Token is invalid or expired, redirect to login
-            router.push('/login');
-            return;
-          }
           throw new Error('Failed to fetch documents');
         }
 
@@ -49,10 +42,10 @@ function DashboardPage() {
     };
 
     fetchDocuments();
-  }, [router]);
+  }, []);
 
-  const handleLogout = () => {
-    localStorage.removeItem('authToken');
+  const handleLogout = async () => {
+    await logout();
     router.push('/login');
   };
 
@@ -99,9 +92,12 @@ function DashboardPage() {
       <header className="bg-white shadow-sm">
         <nav className="container mx-auto px-4 sm:px-6 lg:px-8 flex justify-between items-center h-16">
           <h1 className="text-xl font-bold text-gray-800">Knowledge Base</h1>
-          <Button onClick={handleLogout} variant="outline">
-            Logout
-          </Button>
+          <div className="flex items-center gap-4">
+            <WorkspaceSwitcher />
+            <Button onClick={handleLogout} variant="outline">
+              Logout
+            </Button>
+          </div>
         </nav>
       </header>
       <main className="container mx-auto px-4 sm:px-6 lg:px-8 py-8">
@@ -14,6 +14,7 @@ import { Button } from '@/components/ui/button';
 import { Switch } from "@/components/ui/switch";
 import { Label } from "@/components/ui/label";
 import Link from 'next/link';
+import { apiFetch } from '@/lib/api';
 
 function DocumentEditorPage() {
   const router = useRouter();
@@ -42,11 +43,8 @@ function DocumentEditorPage() {
 
     const fetchDocument = async () => {
       setIsLoading(true);
-      const token = localStorage.getItem('authToken');
       try {
-        const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL}/api/documents/${documentId}`, {
-          headers: { 'Authorization': `Bearer ${token}` },
-        });
+        const response = await apiFetch(`/api/documents/${documentId}`);
 
         if (response.ok) {
           const data = await response.json();
@@ -73,25 +71,20 @@ function DocumentEditorPage() {
   // The core save logic, memoized with useCallback
   const saveDocument = useCallback(async () => {
     setSaveStatus('Saving...');
-    const token = localStorage.getItem('authToken');
-    
+
     // This payload will now use the LATEST state for all three variables
-    const payload = { title, content, isPublic };
+    // The revision lets the server reject the save if someone else changed the document
+    const payload = { title, content, isPublic, revision: document?.revision };
 
     const currentDocId = isNewDocument ? null : document?.ID;
-    const url = currentDocId
-      ? `${process.env.NEXT_PUBLIC_API_URL}/api/documents/${currentDocId}`
-      : `${process.env.NEXT_PUBLIC_API_URL}/api/documents`;
+    const url = currentDocId ? `/api/documents/${currentDocId}` : '/api/documents';
     
     const method = currentDocId ? 'PUT' : 'POST';
 
     try {
-      const response = await fetch(url, {
+      const response = await apiFetch(url, {
         method,
-        headers: {
-          'Content-Type': 'application/json',
-          'Authorization': `Bearer ${token}`,
-        },
+        headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify(payload),
       });
 
@@ -103,6 +96,8 @@ function DocumentEditorPage() {
           // If a new document was just created, redirect to its new edit URL
           router.replace(`/documents/${savedDoc.ID}`);
         }
+      } else if (response.status === 409) {
+        setSaveStatus('Conflict - reload');
       } else {
         setSaveStatus('Error saving');
       }
@@ -110,7 +105,7 @@ function DocumentEditorPage() {
       console.error("Save operation failed:", error);
       setSaveStatus('Error saving');
     }
-  }, [isNewDocument, document?.ID, router, title, content, isPublic]);
+  }, [isNewDocument, document?.ID, document?.revision, router, title, content, isPublic]);
 
   // The auto-save trigger effect
   useEffect(() => {
@@ -0,0 +1,88 @@
+// frontend/src/app/invitations/accept/page.tsx
+'use client';
+
+import Link from 'next/link';
+import { useEffect, useRef, useState } from 'react';
+import { Button } from '@/components/ui/button';
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/components/ui/card';
+import { apiFetch, setWorkspaceId } from '@/lib/api';
+import { Workspace } from '@/types';
+
+// The link in a workspace invitation opens this page with ?token=. The
+// invitation is accepted as the signed-in user, whose verified email must be
+// the one it was sent to.
+export default function AcceptInvitationPage() {
+  const [status, setStatus] = useState<'accepting' | 'accepted' | 'failed' | 'signedOut'>('accepting');
+  const [message, setMessage] = useState('Accepting your invitation...');
+  // The invitation can only be accepted once, so the request must not be
+  // repeated when the effect runs twice in development
+  const requested = useRef(false);
+
+  useEffect(() => {
+    if (requested.current) {
+      return;
+    }
+    requested.current = true;
+
+    const accept = async () => {
+      const token = new URLSearchParams(window.location.search).get('token');
+      if (!token) {
+        setStatus('failed');
+        setMessage('This invitation link is incomplete.');
+        return;
+      }
+      if (!localStorage.getItem('authToken')) {
+        setStatus('signedOut');
+        setMessage(
+          'Sign in with the email address the invitation was sent to, or register with it, then open the link again.'
+        );
+        return;
+      }
+
+      try {
+        const response = await apiFetch('/api/workspaces/invitations/accept', {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({ token }),
+        });
+        const data = await response.json();
+        if (!response.ok) {
+          setStatus('failed');
+          setMessage(data.error || 'Failed to accept the invitation');
+          return;
+        }
+
+        const workspace: Workspace = data;
+        setWorkspaceId(workspace.ID);
+        setStatus('accepted');
+        setMessage(`You are now a member of ${workspace.name}.`);
+      } catch {
+        setStatus('failed');
+        setMessage('Failed to accept the invitation. Please try again.');
+      }
+    };
+    accept();
+  }, []);
+
+  return (
+    <div className="flex items-center justify-center min-h-screen bg-gray-100">
+      <Card className="w-[400px]">
+        <CardHeader>
+          <CardTitle>Workspace invitation</CardTitle>
+          <CardDescription>{message}</CardDescription>
+        </CardHeader>
+        {status !== 'accepting' && (
+          <CardContent>
+            <Button className="w-full" asChild>
+              {status === 'signedOut' ? (
+                <Link href="/login">Sign in</Link>
+              ) : (
+                <Link href="/dashboard">Continue</Link>
+              )}
+            </Button>
+          </CardContent>
+        )}
+      </Card>
+    </div>
+  );
+}
@@ -5,7 +5,7 @@ import { zodResolver } from '@hookform/resolvers/zod';
 import { useForm } from 'react-hook-form';
 import * as z from 'zod';
 import { useRouter } from 'next/navigation';
-import { useState } from 'react';
+import { useEffect, useState } from 'react';
 import Link from 'next/link';
 
 import { Button } from '@/components/ui/button';
@@ -19,6 +19,19 @@ import {
 } from '@/components/ui/form';
 import { Input } from '@/components/ui/input';
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/components/ui/card';
+import { TwoFactorForm } from '@/components/TwoFactorForm';
+import { storeSession } from '@/lib/api';
+
+// Messages for the error codes single sign-on sends back in ?error=
+const ssoErrors: Record<string, string> = {
+  access_denied: 'Single sign-on was cancelled.',
+  invalid_state: 'Your sign-in attempt expired. Please try again.',
+  sso_failed: 'Single sign-on failed. Please try again.',
+  account_exists:
+    'An account with this email already exists. Sign in with your password, or verify the email with your identity provider first.',
+  account_deactivated: 'This account has been deactivated. Contact an administrator.',
+  password_reset_required: 'You must reset your password first. Check your email for a reset link.',
+};
 
 // Define the validation schema for the login form
 const formSchema = z.object({
@@ -30,6 +43,15 @@ export default function LoginPage() {
   const router = useRouter();
   const [error, setError] = useState<string | null>(null);
   const [isLoading, setIsLoading] = useState(false);
+  // Set when the password was right but the account needs a second factor
+  const [challengeToken, setChallengeToken] = useState<string | null>(null);
+
+  useEffect(() => {
+    const code = new URLSearchParams(window.location.search).get('error');
+    if (code) {
+      setError(ssoErrors[code] ?? `Single sign-on failed (${code}).`);
+    }
+  }, []);
 
   const form = useForm<z.infer<typeof formSchema>>({
     resolver: zodResolver(formSchema),
@@ -57,8 +79,13 @@ export default function LoginPage() {
         throw new Error(data.error || 'Login failed');
       }
 
+      if (data.twoFactorRequired) {
+        setChallengeToken(data.challengeToken);
+        return;
+      }
+
       // **IMPORTANT**: Store the token
-      localStorage.setItem('authToken', data.token);
+      storeSession(data);
 
       // On successful login, redirect to the dashboard
       router.push('/dashboard');
@@ -75,49 +102,71 @@ export default function LoginPage() {
       <Card className="w-[400px]">
         <CardHeader>
           <CardTitle>Welcome Back</CardTitle>
-          <CardDescription>Sign in to your account to continue.</CardDescription>
+          <CardDescription>
+            {challengeToken
+              ? 'Enter the code from your authenticator app.'
+              : 'Sign in to your account to continue.'}
+          </CardDescription>
         </CardHeader>
         <CardContent>
-          <Form {...form}>
-            <form onSubmit={form.handleSubmit(onSubmit)} className="space-y-4">
-              <FormField
-                control={form.control}
-                name="email"
-                render={({ field }) => (
-                  <FormItem>
-                    <FormLabel>Email</FormLabel>
-                    <FormControl>
-                      <Input placeholder="you@example.com" {...field} />
-                    </FormControl>
-                    <FormMessage />
-                  </FormItem>
-                )}
-              />
-              <FormField
-                control={form.control}
-                name="password"
-                render={({ field }) => (
-                  <FormItem>
-                    <FormLabel>Password</FormLabel>
-                    <FormControl>
-                      <Input type="password" placeholder="••••••••" {...field} />
-                    </FormControl>
-                    <FormMessage />
-                  </FormItem>
-                )}
-              />
-              {error && <p className="text-sm text-red-500">{error}</p>}
-              <Button type="submit" className="w-full" disabled={isLoading}>
-                {isLoading ? 'Signing in...' : 'Sign In'}
-              </Button>
-              <div className="text-center text-sm text-gray-600">
-                Don&apos;t have an account?{' '}
-                <Link href="/register" className="font-medium text-blue-600 hover:underline">
-                  Register
-                </Link>
-              </div>
-            </form>
-          </Form>
+          {challengeToken ? (
+            <TwoFactorForm
+              challengeToken={challengeToken}
+              onRestart={() => {
+                setChallengeToken(null);
+                setError('Your sign-in attempt expired. Please sign in again.');
+              }}
+            />
+          ) : (
+            <Form {...form}>
+              <form onSubmit={form.handleSubmit(onSubmit)} className="space-y-4">
+                <FormField
+                  control={form.control}
+                  name="email"
+                  render={({ field }) => (
+                    <FormItem>
+                      <FormLabel>Email</FormLabel>
+                      <FormControl>
+                        <Input placeholder="you@example.com" {...field} />
+                      </FormControl>
+                      <FormMessage />
+                    </FormItem>
+                  )}
+                />
+                <FormField
+                  control={form.control}
+                  name="password"
+                  render={({ field }) => (
+                    <FormItem>
+                      <FormLabel>Password</FormLabel>
+                      <FormControl>
+                        <Input type="password" placeholder="••••••••" {...field} />
+                      </FormControl>
+                      <FormMessage />
+                    </FormItem>
+                  )}
+                />
+                {error && <p className="text-sm text-red-500">{error}</p>}
+                <Button type="submit" className="w-full" disabled={isLoading}>
+                  {isLoading ? 'Signing in...' : 'Sign In'}
+                </Button>
+                <Button variant="outline" className="w-full" asChild>
+                  <a href={`${process.env.NEXT_PUBLIC_API_URL}/api/auth/oidc/login`}>Sign in with SSO</a>
+                </Button>
+                <div className="text-center text-sm">
+                  <Link href="/reset-password" className="font-medium text-blue-600 hover:underline">
+                    Forgot your password?
+                  </Link>
+                </div>
+                <div className="text-center text-sm text-gray-600">
+                  Don&apos;t have an account?{' '}
+                  <Link href="/register" className="font-medium text-blue-600 hover:underline">
+                    Register
+                  </Link>
+                </div>
+              </form>
+            </Form>
+          )}
         </CardContent>
       </Card>
     </div>
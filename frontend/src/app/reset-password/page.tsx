@@ -0,0 +1,178 @@
+// frontend/src/app/reset-password/page.tsx
+'use client';
+
+import { zodResolver } from '@hookform/resolvers/zod';
+import { useForm } from 'react-hook-form';
+import * as z from 'zod';
+import Link from 'next/link';
+import { useEffect, useState } from 'react';
+
+import { Button } from '@/components/ui/button';
+import {
+  Form,
+  FormControl,
+  FormField,
+  FormItem,
+  FormLabel,
+  FormMessage,
+} from '@/components/ui/form';
+import { Input } from '@/components/ui/input';
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/components/ui/card';
+
+const requestSchema = z.object({
+  email: z.string().email({ message: 'Please enter a valid email.' }),
+});
+
+const resetSchema = z
+  .object({
+    password: z.string().min(6, { message: 'Password must be at least 6 characters.' }),
+    confirmPassword: z.string(),
+  })
+  .refine((values) => values.password === values.confirmPassword, {
+    message: 'Passwords do not match.',
+    path: ['confirmPassword'],
+  });
+
+// Without a token this page asks for a reset link; the link in the email
+// brings the user back here with ?token= to choose a new password
+export default function ResetPasswordPage() {
+  const [token, setToken] = useState<string | null>(null);
+  const [message, setMessage] = useState<string | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [isLoading, setIsLoading] = useState(false);
+
+  useEffect(() => {
+    setToken(new URLSearchParams(window.location.search).get('token'));
+  }, []);
+
+  const requestForm = useForm<z.infer<typeof requestSchema>>({
+    resolver: zodResolver(requestSchema),
+    defaultValues: { email: '' },
+  });
+
+  const resetForm = useForm<z.infer<typeof resetSchema>>({
+    resolver: zodResolver(resetSchema),
+    defaultValues: { password: '', confirmPassword: '' },
+  });
+
+  async function post(path: string, body: object) {
+    setIsLoading(true);
+    setError(null);
+    try {
+      const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL}${path}`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(body),
+      });
+      const data = await response.json();
+      if (!response.ok) {
+        throw new Error(data.error || 'Something went wrong');
+      }
+      setMessage(data.message);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'An unexpected error occurred');
+    } finally {
+      setIsLoading(false);
+    }
+  }
+
+  const renderContent = () => {
+    if (message) {
+      return (
+        <div className="space-y-4">
+          <p className="text-sm text-gray-600">{message}</p>
+          <Button className="w-full" asChild>
+            <Link href="/login">Back to sign in</Link>
+          </Button>
+        </div>
+      );
+    }
+
+    if (token) {
+      return (
+        <Form {...resetForm}>
+          <form
+            onSubmit={resetForm.handleSubmit((values) =>
+              post('/api/auth/reset-password', { token, password: values.password })
+            )}
+            className="space-y-4"
+          >
+            <FormField
+              control={resetForm.control}
+              name="password"
+              render={({ field }) => (
+                <FormItem>
+                  <FormLabel>New password</FormLabel>
+                  <FormControl>
+                    <Input type="password" placeholder="••••••••" {...field} />
+                  </FormControl>
+                  <FormMessage />
+                </FormItem>
+              )}
+            />
+            <FormField
+              control={resetForm.control}
+              name="confirmPassword"
+              render={({ field }) => (
+                <FormItem>
+                  <FormLabel>Confirm new password</FormLabel>
+                  <FormControl>
+                    <Input type="password" placeholder="••••••••" {...field} />
+                  </FormControl>
+                  <FormMessage />
+                </FormItem>
+              )}
+            />
+            {error && <p className="text-sm text-red-500">{error}</p>}
+            <Button type="submit" className="w-full" disabled={isLoading}>
+              {isLoading ? 'Saving...' : 'Set new password'}
+            </Button>
+          </form>
+        </Form>
+      );
+    }
+
+    return (
+      <Form {...requestForm}>
+        <form
+          onSubmit={requestForm.handleSubmit((values) => post('/api/auth/forgot-password', values))}
+          className="space-y-4"
+        >
+          <FormField
+            control={requestForm.control}
+            name="email"
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>Email</FormLabel>
+                <FormControl>
+                  <Input placeholder="you@example.com" {...field} />
+                </FormControl>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+          {error && <p className="text-sm text-red-500">{error}</p>}
+          <Button type="submit" className="w-full" disabled={isLoading}>
+            {isLoading ? 'Sending...' : 'Send reset link'}
+          </Button>
+        </form>
+      </Form>
+    );
+  };
+
+  return (
+    <div className="flex items-center justify-center min-h-screen bg-gray-100">
+      <Card className="w-[400px]">
+        <CardHeader>
+          <CardTitle>Reset your password</CardTitle>
+          <CardDescription>
+            {token
+              ? 'Choose a new password. You will be signed out everywhere else.'
+              : 'We will email you a link to choose a new password.'}
+          </CardDescription>
+        </CardHeader>
+        <CardContent>{renderContent()}</CardContent>
+      </Card>
+    </div>
+  );
+}
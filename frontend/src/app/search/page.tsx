@@ -8,6 +8,7 @@ import Link from 'next/link';
 import { Card, CardHeader, CardTitle, CardDescription, CardFooter } from '@/components/ui/card';
 import { format } from 'date-fns';
 import { Button } from '@/components/ui/button';
+import { apiFetch } from '@/lib/api';
 
 function SearchResults() {
   const searchParams = useSearchParams();
@@ -23,11 +24,7 @@ function SearchResults() {
 
     const fetchResults = async () => {
       setIsLoading(true);
-      const token = localStorage.getItem('authToken');
-      const response = await fetch(
-        `${process.env.NEXT_PUBLIC_API_URL}/api/documents/search?q=${encodeURIComponent(query)}`,
-        { headers: { Authorization: `Bearer ${token}` } }
-      );
+      const response = await apiFetch(`/api/documents/search?q=${encodeURIComponent(query)}`);
       if (response.ok) {
         const data = await response.json();
         setResults(data);
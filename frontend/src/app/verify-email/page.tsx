@@ -0,0 +1,65 @@
+// frontend/src/app/verify-email/page.tsx
+'use client';
+
+import Link from 'next/link';
+import { useEffect, useRef, useState } from 'react';
+import { Button } from '@/components/ui/button';
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/components/ui/card';
+
+// The link in the verification email opens this page with ?token=
+export default function VerifyEmailPage() {
+  const [status, setStatus] = useState<'verifying' | 'verified' | 'failed'>('verifying');
+  const [message, setMessage] = useState('Verifying your email address...');
+  // Tokens are single use, so the request must not be repeated when the
+  // effect runs twice in development
+  const requested = useRef(false);
+
+  useEffect(() => {
+    if (requested.current) {
+      return;
+    }
+    requested.current = true;
+
+    const verify = async () => {
+      const token = new URLSearchParams(window.location.search).get('token');
+      if (!token) {
+        setStatus('failed');
+        setMessage('This verification link is incomplete.');
+        return;
+      }
+
+      try {
+        const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL}/api/auth/verify-email`, {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({ token }),
+        });
+        const data = await response.json();
+        setStatus(response.ok ? 'verified' : 'failed');
+        setMessage(response.ok ? data.message : data.error || 'Verification failed');
+      } catch {
+        setStatus('failed');
+        setMessage('Verification failed. Please try again.');
+      }
+    };
+    verify();
+  }, []);
+
+  return (
+    <div className="flex items-center justify-center min-h-screen bg-gray-100">
+      <Card className="w-[400px]">
+        <CardHeader>
+          <CardTitle>Verify your email</CardTitle>
+          <CardDescription>{message}</CardDescription>
+        </CardHeader>
+        {status !== 'verifying' && (
+          <CardContent>
+            <Button className="w-full" asChild>
+              <Link href="/dashboard">Continue</Link>
+            </Button>
+          </CardContent>
+        )}
+      </Card>
+    </div>
+  );
+}
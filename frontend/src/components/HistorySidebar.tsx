@@ -4,6 +4,7 @@
 import { useEffect, useState } from 'react';
 import { Version } from '@/types';
 import { format } from 'date-fns';
+import { apiFetch } from '@/lib/api';
 
 interface HistorySidebarProps {
   documentId: number;
@@ -16,11 +17,7 @@ export function HistorySidebar({ documentId, currentContent }: HistorySidebarPro
 
   useEffect(() => {
     const fetchHistory = async () => {
-      const token = localStorage.getItem('authToken');
-      const response = await fetch(
-        `${process.env.NEXT_PUBLIC_API_URL}/api/documents/${documentId}/versions`,
-        { headers: { Authorization: `Bearer ${token}` } }
-      );
+      const response = await apiFetch(`/api/documents/${documentId}/versions`);
       if (response.ok) {
         const data = await response.json();
         setVersions(data);
@@ -13,6 +13,7 @@ import {
 import { Input } from '@/components/ui/input';
 import { User } from '@/types';
 import { useDebounce } from 'use-debounce';
+import { apiFetch } from '@/lib/api';
 
 interface SharingDialogProps {
   documentId: number;
@@ -28,11 +29,7 @@ export function SharingDialog({ documentId }: SharingDialogProps) {
   useEffect(() => {
     if (debouncedSearchQuery) {
       const search = async () => {
-        const token = localStorage.getItem('authToken');
-        const response = await fetch(
-          `${process.env.NEXT_PUBLIC_API_URL}/api/users/search?q=${debouncedSearchQuery}`,
-          { headers: { Authorization: `Bearer ${token}` } }
-        );
+        const response = await apiFetch(`/api/users/search?q=${encodeURIComponent(debouncedSearchQuery)}`);
         const data = await response.json();
         setSearchResults(data);
       };
@@ -43,13 +40,9 @@ export function SharingDialog({ documentId }: SharingDialogProps) {
   }, [debouncedSearchQuery]);
 
   const handleShare = async (email: string) => {
-    const token = localStorage.getItem('authToken');
-    await fetch(`${process.env.NEXT_PUBLIC_API_URL}/api/documents/${documentId}/permissions`, {
+    await apiFetch(`/api/documents/${documentId}/permissions`, {
       method: 'POST',
-      headers: {
-        'Content-Type': 'application/json',
-        Authorization: `Bearer ${token}`,
-      },
+      headers: { 'Content-Type': 'application/json' },
       body: JSON.stringify({ email, level: 'VIEW' }), // Default to VIEW for now
     });
     setSearchQuery('');
@@ -0,0 +1,90 @@
+// frontend/src/components/TwoFactorForm.tsx
+'use client';
+
+import { useState, FormEvent } from 'react';
+import { useRouter } from 'next/navigation';
+import { Button } from '@/components/ui/button';
+import { Input } from '@/components/ui/input';
+import { Label } from '@/components/ui/label';
+import { storeSession } from '@/lib/api';
+
+interface TwoFactorFormProps {
+  challengeToken: string;
+  // Called when the challenge has expired and the user has to sign in again
+  onRestart: () => void;
+}
+
+// TwoFactorForm completes a sign-in that needs a second factor, with either
+// a code from the authenticator app or a recovery code
+export function TwoFactorForm({ challengeToken, onRestart }: TwoFactorFormProps) {
+  const router = useRouter();
+  const [code, setCode] = useState('');
+  const [useRecoveryCode, setUseRecoveryCode] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [isLoading, setIsLoading] = useState(false);
+
+  const handleSubmit = async (event: FormEvent<HTMLFormElement>) => {
+    event.preventDefault();
+    setIsLoading(true);
+    setError(null);
+
+    try {
+      const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL}/api/auth/login/2fa`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(
+          useRecoveryCode ? { challengeToken, recoveryCode: code } : { challengeToken, code }
+        ),
+      });
+      const data = await response.json();
+
+      if (!response.ok) {
+        // Only a wrong code can be retried; anything else needs a new challenge
+        if (response.status === 401 && data.error !== 'Invalid code') {
+          onRestart();
+        }
+        throw new Error(data.error || 'Verification failed');
+      }
+
+      storeSession(data);
+      router.push('/dashboard');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'An unexpected error occurred');
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  return (
+    <form onSubmit={handleSubmit} className="space-y-4">
+      <div className="space-y-2">
+        <Label htmlFor="code">{useRecoveryCode ? 'Recovery code' : 'Authentication code'}</Label>
+        <Input
+          id="code"
+          value={code}
+          onChange={(e) => setCode(e.target.value)}
+          autoComplete="one-time-code"
+          inputMode={useRecoveryCode ? 'text' : 'numeric'}
+          placeholder={useRecoveryCode ? 'xxxxx-xxxxx' : '123456'}
+          autoFocus
+        />
+      </div>
+      {error && <p className="text-sm text-red-500">{error}</p>}
+      <Button type="submit" className="w-full" disabled={isLoading || !code}>
+        {isLoading ? 'Verifying...' : 'Verify'}
+      </Button>
+      <Button
+        type="button"
+        variant="link"
+        className="w-full"
+        onClick={() => {
+          setUseRecoveryCode(!useRecoveryCode);
+          setCode('');
+          setError(null);
+        }}
+      >
+        {useRecoveryCode ? 'Use your authenticator app instead' : 'Use a recovery code instead'}
+      </Button>
+    </form>
+  );
+}
@@ -0,0 +1,62 @@
+// frontend/src/components/WorkspaceSwitcher.tsx
+'use client';
+
+import { useEffect, useState, ChangeEvent } from 'react';
+import { Workspace } from '@/types';
+import { apiFetch, getWorkspaceId, setWorkspaceId } from '@/lib/api';
+
+// WorkspaceSwitcher picks the workspace every API request works in. Switching
+// reloads the page, so everything on it is fetched from the new workspace.
+export function WorkspaceSwitcher() {
+  const [workspaces, setWorkspaces] = useState<Workspace[]>([]);
+  const [current, setCurrent] = useState<string>('');
+
+  useEffect(() => {
+    const fetchWorkspaces = async () => {
+      const response = await apiFetch('/api/workspaces');
+      if (!response.ok) {
+        return;
+      }
+      const data: Workspace[] = await response.json();
+      setWorkspaces(data);
+
+      // Fall back to the first workspace if the user left the stored one
+      const stored = getWorkspaceId();
+      const active = data.find((workspace) => String(workspace.ID) === stored) ?? data[0];
+      if (active) {
+        setWorkspaceId(active.ID);
+        setCurrent(String(active.ID));
+        if (String(active.ID) !== stored && stored) {
+          window.location.reload();
+        }
+      }
+    };
+    fetchWorkspaces();
+  }, []);
+
+  const handleChange = (event: ChangeEvent<HTMLSelectElement>) => {
+    setWorkspaceId(Number(event.target.value));
+    window.location.reload();
+  };
+
+  if (workspaces.length < 2) {
+    return workspaces.length === 1 ? (
+      <span className="text-sm text-gray-600">{workspaces[0].name}</span>
+    ) : null;
+  }
+
+  return (
+    <select
+      aria-label="Workspace"
+      value={current}
+      onChange={handleChange}
+      className="border-input h-9 rounded-md border bg-transparent px-3 text-sm shadow-xs"
+    >
+      {workspaces.map((workspace) => (
+        <option key={workspace.ID} value={workspace.ID}>
+          {workspace.name}
+        </option>
+      ))}
+    </select>
+  );
+}
@@ -0,0 +1,107 @@
+// frontend/src/lib/api.ts
+const API_URL = process.env.NEXT_PUBLIC_API_URL;
+
+// A refresh token can only be used once, so requests that fail at the same
+// time share a single refresh
+let refreshing: Promise<boolean> | null = null;
+
+interface Tokens {
+  token: string;
+  refreshToken: string;
+}
+
+// storeSession saves the tokens returned by login, SSO or a refresh
+export function storeSession(tokens: Tokens) {
+  localStorage.setItem('authToken', tokens.token);
+  localStorage.setItem('refreshToken', tokens.refreshToken);
+}
+
+export function clearSession() {
+  localStorage.removeItem('authToken');
+  localStorage.removeItem('refreshToken');
+  localStorage.removeItem('workspaceId');
+}
+
+// The workspace requests work in; without one the API uses the first
+// workspace the user joined
+export function getWorkspaceId(): string | null {
+  return localStorage.getItem('workspaceId');
+}
+
+export function setWorkspaceId(workspaceId: number) {
+  localStorage.setItem('workspaceId', String(workspaceId));
+}
+
+async function refreshSession(): Promise<boolean> {
+  const refreshToken = localStorage.getItem('refreshToken');
+  if (!refreshToken) {
+    return false;
+  }
+  try {
+    const response = await fetch(`${API_URL}/api/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refreshToken }),
+    });
+    if (!response.ok) {
+      return false;
+    }
+    storeSession(await response.json());
+    return true;
+  } catch {
+    return false;
+  }
+}
+
+function send(path: string, init: RequestInit): Promise<Response> {
+  const headers = new Headers(init.headers);
+  const token = localStorage.getItem('authToken');
+  if (token) {
+    headers.set('Authorization', `Bearer ${token}`);
+  }
+  const workspaceId = getWorkspaceId();
+  if (workspaceId) {
+    headers.set('X-Workspace-ID', workspaceId);
+  }
+  return fetch(`${API_URL}${path}`, { ...init, headers });
+}
+
+// apiFetch calls the API as the logged in user. When the access token has
+// expired the session is refreshed and the request retried once; if the
+// session cannot be refreshed the user is sent back to the login page.
+export async function apiFetch(path: string, init: RequestInit = {}): Promise<Response> {
+  const response = await send(path, init);
+  if (response.status !== 401) {
+    return response;
+  }
+
+  if (!refreshing) {
+    refreshing = refreshSession().finally(() => {
+      refreshing = null;
+    });
+  }
+  if (await refreshing) {
+    return send(path, init);
+  }
+
+  clearSession();
+  window.location.href = '/login';
+  return response;
+}
+
+// logout ends the session on the server as well as in the browser
+export async function logout() {
+  const refreshToken = localStorage.getItem('refreshToken');
+  if (refreshToken) {
+    try {
+      await fetch(`${API_URL}/api/auth/logout`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refreshToken }),
+      });
+    } catch (error) {
+      console.error('Logout failed:', error);
+    }
+  }
+  clearSession();
+}
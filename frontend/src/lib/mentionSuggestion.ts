@@ -3,6 +3,7 @@ import { ReactRenderer } from '@tiptap/react';
 import tippy from 'tippy.js';
 import { MentionList } from '@/components/MentionList'; // We will create this component
 import { User } from '@/types';
+import { apiFetch } from '@/lib/api';
 
 interface SuggestionProps {
   query: string;
@@ -20,11 +21,7 @@ export const mentionSuggestion = {
     if (query.length === 0) {
       return [];
     }
-    const token = localStorage.getItem('authToken');
-    const response = await fetch(
-      `${process.env.NEXT_PUBLIC_API_URL}/api/users/search?q=${query}`,
-      { headers: { Authorization: `Bearer ${token}` } }
-    );
+    const response = await apiFetch(`/api/users/search?q=${encodeURIComponent(query)}`);
     const users = await response.json();
     return users;
   },
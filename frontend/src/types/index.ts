@@ -13,6 +13,7 @@ export interface User {
     isPublic: boolean;
     authorId: number;
     author: User;
+    revision: number;
   }
   
   export interface Version {
@@ -21,4 +22,9 @@ export interface User {
     title: string;
     content: string;
     author: User;
-  }
\ No newline at end of file
+  }
+  export interface Workspace {
+    ID: number;
+    name: string;
+    role: 'MEMBER' | 'ADMIN';
+  }